@@ -0,0 +1,470 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The fulbot games and players commands let whoever runs the bot inspect and repair games. The games live in the
+// memory of the running bot, so the commands reach it through the Unix socket set in admin_socket, one JSON request
+// and response per connection. Edits are saved without telling the chat, run /verpartido there to show the roster.
+
+const adminUsage = `Usage:
+  fulbot games [-socket path] list [-all]
+  fulbot games [-socket path] show GAME
+  fulbot games [-socket path] edit GAME field=value...
+  fulbot games [-socket path] reopen GAME
+  fulbot players [-socket path] remove GAME PLAYER_ID|GUEST_NAME [-keep-guests]
+  fulbot export [-socket path] [-format json|csv] [-chat CHAT_ID] > FILE
+  fulbot import [-socket path] FILE
+  fulbot backup [-socket path]
+  fulbot restore [-socket path] SNAPSHOT
+
+The fields games edit changes are: size, max_players, date, schedule, address, venue, cost and organizer.
+The socket is the admin_socket of the running bot, by default the one in FULBOT_ADMIN_SOCKET or its config file.`
+
+type adminRequest struct {
+	Args []string `json:"args"`
+	// Input is the contents of the file the command reads, like the one to import.
+	Input string `json:"input,omitempty"`
+}
+
+type adminResponse struct {
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+var errAdminUsage = errors.New("unknown admin command")
+
+// runAdminCommand runs an admin command in the bot and returns what to show the operator.
+func runAdminCommand(args []string, input string) (string, error) {
+	switch {
+	case len(args) > 0 && args[0] == "export":
+		return adminExport(args[1:])
+	case len(args) > 0 && args[0] == "import":
+		data, err := decodeExport([]byte(input))
+		if err != nil {
+			return "", err
+		}
+		games, chats, err := importData(data)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Imported %d games and %d chats.", games, chats), nil
+	case len(args) > 0 && args[0] == "restore":
+		data, err := readBackup([]byte(input))
+		if err != nil {
+			return "", err
+		}
+		if err := restoreData(data); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Restored %d games and %d chats from the snapshot of %s.", len(data.Games), len(data.Chats), data.ExportedAt.Format(time.RFC3339)), nil
+	case len(args) > 0 && args[0] == "backup":
+		directory := getConfig().Backups.Directory
+		if directory == "" {
+			return "", errors.New("backups are off, set backups.directory in the configuration")
+		}
+		filename, err := writeBackup(directory, clock())
+		if err != nil {
+			return "", err
+		}
+		return "Saved " + filename, pruneBackups(directory, getConfig().Backups.Keep)
+	case len(args) < 2:
+		return "", errAdminUsage
+	}
+	switch args[0] + " " + args[1] {
+	case "games list":
+		return adminListGames(len(args) > 2 && args[2] == "-all"), nil
+	case "games show":
+		game, err := adminGame(args[2:])
+		if err != nil {
+			return "", err
+		}
+		shown, _ := json.MarshalIndent(game, "", "  ")
+		return string(shown), nil
+	case "games edit":
+		return adminEditGame(args[2:])
+	case "games reopen":
+		game, err := adminGame(args[2:])
+		if err != nil {
+			return "", err
+		}
+		if game.Active {
+			return "", fmt.Errorf("game %d is already pending", game.Id)
+		}
+		game.Active = true
+		updateGame(game.Id, game)
+		return fmt.Sprintf("Reopened game %d.", game.Id), nil
+	case "players remove":
+		return adminRemovePlayer(args[2:])
+	}
+	return "", errAdminUsage
+}
+
+// adminGame finds the game whose number is the first argument.
+func adminGame(args []string) (Game, error) {
+	if len(args) == 0 {
+		return Game{}, errAdminUsage
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return Game{}, fmt.Errorf("%q is not a game number", args[0])
+	}
+	game, exists := getGame(id)
+	if !exists {
+		return Game{}, fmt.Errorf("there is no game %d", id)
+	}
+	return game, nil
+}
+
+func adminListGames(all bool) string {
+	mutex.Lock()
+	list := make([]Game, 0, len(games))
+	for _, game := range games {
+		if game.Active || all {
+			list = append(list, game)
+		}
+	}
+	mutex.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+
+	if len(list) == 0 {
+		return "No games."
+	}
+	lines := make([]string, 0, len(list))
+	for _, game := range list {
+		status := "pending"
+		if !game.Active {
+			status = "closed"
+		}
+		line := fmt.Sprintf("%d\tchat %d\t%s\tsize %s\t%d/%d players", game.Id, game.ChatID, status, game.Size, game.headcount(), game.MaxPlayers)
+		if when := strings.Join(append(append([]string{}, game.Date...), game.Schedule...), " "); when != "" {
+			line += "\t" + when
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// adminGameFields sets a field of a game from its text.
+var adminGameFields = map[string]func(game *Game, value string) error{
+	"size": func(game *Game, value string) error {
+		maxPlayers, err := getMaxPlayersByTamano(value)
+		if err != nil {
+			return err
+		}
+		game.Size = value
+		game.MaxPlayers = maxPlayers
+		return nil
+	},
+	"max_players": func(game *Game, value string) error {
+		maxPlayers, err := strconv.Atoi(value)
+		if err != nil || maxPlayers < 1 {
+			return fmt.Errorf("%q is not a number of players", value)
+		}
+		game.MaxPlayers = maxPlayers
+		return nil
+	},
+	"date": func(game *Game, value string) error {
+		game.Date = strings.Fields(value)
+		return nil
+	},
+	"schedule": func(game *Game, value string) error {
+		game.Schedule = strings.Fields(value)
+		return nil
+	},
+	"address": func(game *Game, value string) error {
+		game.Address = strings.Fields(value)
+		game.Venue = ""
+		return nil
+	},
+	"venue": func(game *Game, value string) error {
+		applyVenue(game, strings.Fields(value))
+		return nil
+	},
+	"cost": func(game *Game, value string) error {
+		cost, err := strconv.Atoi(value)
+		if err != nil || cost < 0 {
+			return fmt.Errorf("%q is not an amount", value)
+		}
+		game.Cost = cost
+		return nil
+	},
+	"organizer": func(game *Game, value string) error {
+		organizer, err := strconv.Atoi(value)
+		if err != nil || organizer <= 0 {
+			return fmt.Errorf("%q is not a user ID", value)
+		}
+		game.OrganizerID = organizer
+		return nil
+	},
+}
+
+func adminEditGame(args []string) (string, error) {
+	game, err := adminGame(args)
+	if err != nil {
+		return "", err
+	}
+	if len(args) < 2 {
+		return "", errAdminUsage
+	}
+	for _, change := range args[1:] {
+		parts := strings.SplitN(change, "=", 2)
+		set, exists := adminGameFields[parts[0]]
+		if len(parts) != 2 || !exists {
+			return "", fmt.Errorf("%q is not a field=value change", change)
+		}
+		if err := set(&game, parts[1]); err != nil {
+			return "", fmt.Errorf("%s: %w", parts[0], err)
+		}
+	}
+	if game.headcount() > game.MaxPlayers {
+		return "", fmt.Errorf("game %d has %d players and guests, more than %d, remove some first", game.Id, game.headcount(), game.MaxPlayers)
+	}
+	updateGame(game.Id, game)
+	return fmt.Sprintf("Updated game %d.", game.Id), nil
+}
+
+func adminRemovePlayer(args []string) (string, error) {
+	game, err := adminGame(args)
+	if err != nil {
+		return "", err
+	}
+	if len(args) < 2 {
+		return "", errAdminUsage
+	}
+	keepGuests := len(args) > 2 && args[len(args)-1] == "-keep-guests"
+	if keepGuests {
+		args = args[:len(args)-1]
+	}
+	who := strings.Join(args[1:], " ")
+
+	if playerID, err := strconv.Atoi(who); err == nil {
+		if !contains(game.Players, playerID) && !game.isWaiting(playerID) && len(game.guestsInvitedBy(playerID)) == 0 {
+			return "", fmt.Errorf("%d is not in game %d", playerID, game.Id)
+		}
+		guests := removePlayer(&game, playerID, keepGuests)
+		updateGame(game.Id, game)
+		output := fmt.Sprintf("Removed %d from game %d.", playerID, game.Id)
+		if len(guests) > 0 {
+			output += " Their guests were " + map[bool]string{true: "kept", false: "removed"}[keepGuests && game.OrganizerID != playerID] + ": " + joinGuestNames(guests) + "."
+		}
+		return output, nil
+	}
+
+	index, found := game.findGuest(who, 0)
+	if !found {
+		return "", fmt.Errorf("there is no guest named %q in game %d", who, game.Id)
+	}
+	game.Guests = removeGuestAt(game.Guests, index)
+	updateGame(game.Id, game)
+	return fmt.Sprintf("Removed the guest %s from game %d.", who, game.Id), nil
+}
+
+func adminExport(args []string) (string, error) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	format := flags.String("format", formatJSON, "")
+	chatID := flags.Int64("chat", 0, "")
+	if err := flags.Parse(args); err != nil {
+		return "", errAdminUsage
+	}
+	var output strings.Builder
+	if err := encodeExport(&output, exportData(*chatID), *format); err != nil {
+		return "", err
+	}
+	return output.String(), nil
+}
+
+// listenAdminSocket opens the admin socket, readable only by the user running the bot. It is created in a private
+// directory and moved into place once secured, so nobody else can connect in between.
+func listenAdminSocket(path string) (net.Listener, error) {
+	// A socket left behind by a previous run would keep the bot from listening, anything else is not ours to remove.
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	directory, err := os.MkdirTemp(filepath.Dir(path), ".fulbot-admin-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(directory)
+	private := filepath.Join(directory, "admin.sock")
+	listener, err := net.Listen("unix", private)
+	if err != nil {
+		return nil, err
+	}
+	// The listener would remove the socket at its old path when closed.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(private, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	if err := os.Rename(private, path); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// serveAdminSocket answers the admin commands sent to the socket until the bot stops.
+func serveAdminSocket(path string) {
+	listener, err := listenAdminSocket(path)
+	checkForFatalError("error opening the admin socket", err)
+	slog.Info("serving admin commands", "socket", path)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			slog.Error("error accepting an admin connection", "error", err)
+			continue
+		}
+		go handleAdminConnection(conn)
+	}
+}
+
+func handleAdminConnection(conn net.Conn) {
+	defer conn.Close()
+	var request adminRequest
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&request); err != nil {
+		slog.Error("error reading an admin command", "error", err)
+		return
+	}
+	slog.Info("admin command", "args", request.Args)
+	output, err := runAdminCommand(request.Args, request.Input)
+	response := adminResponse{Output: output}
+	if err != nil {
+		response.Error = err.Error()
+	}
+	json.NewEncoder(conn).Encode(response)
+}
+
+// sendAdminCommand runs an admin command in the bot listening on the socket.
+func sendAdminCommand(socket string, args []string, input string) (string, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return "", fmt.Errorf("the bot is not answering on %s: %w", socket, err)
+	}
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(adminRequest{Args: args, Input: input}); err != nil {
+		return "", err
+	}
+	var response adminResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return "", err
+	}
+	if response.Error != "" {
+		return response.Output, errors.New(response.Error)
+	}
+	return response.Output, nil
+}
+
+// adminSocketPath finds the admin socket of the bot in the environment or its configuration file.
+func adminSocketPath(getenv func(string) string) string {
+	if socket := getenv("FULBOT_ADMIN_SOCKET"); socket != "" {
+		return socket
+	}
+	filename := defaultConfigFile
+	if env := getenv("FULBOT_CONFIG"); env != "" {
+		filename = env
+	}
+	config := defaultConfig()
+	if err := readConfigFile(filename, config); err != nil {
+		return ""
+	}
+	return config.AdminSocket
+}
+
+// splitSocketFlag takes -socket out of the arguments of an admin command, wherever it is. The rest, flags like
+// -format or -all included, are for the bot to read.
+func splitSocketFlag(args []string, socket string) (string, []string, bool) {
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "-"), "=")
+		switch {
+		case args[i] == "-h" || args[i] == "-help" || args[i] == "--help":
+			return "", nil, false
+		case !strings.HasPrefix(args[i], "-") || strings.TrimPrefix(name, "-") != "socket":
+			rest = append(rest, args[i])
+		case hasValue:
+			socket = value
+		case i+1 < len(args):
+			socket = args[i+1]
+			i++
+		default:
+			return "", nil, false
+		}
+	}
+	return socket, rest, true
+}
+
+// adminCommand runs fulbot games, players, export, import, backup and restore, returning the exit code: 0 when done, 1 when the bot refused
+// the command and 2 for usage errors.
+func adminCommand(args []string) int {
+	socket, rest, ok := splitSocketFlag(args[1:], adminSocketPath(os.Getenv))
+	if !ok {
+		fmt.Fprintln(os.Stderr, adminUsage)
+		return 2
+	}
+	if socket == "" {
+		fmt.Fprintln(os.Stderr, "The games are kept in the memory of the running bot, set admin_socket in its configuration and pass it with -socket or FULBOT_ADMIN_SOCKET.")
+		return 2
+	}
+
+	var input []byte
+	if args[0] == "import" || args[0] == "restore" {
+		if len(rest) != 1 {
+			fmt.Fprintln(os.Stderr, adminUsage)
+			return 2
+		}
+		var err error
+		if input, err = os.ReadFile(rest[0]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+	}
+	if args[0] == "restore" {
+		// Snapshots are compressed, so they are checked and sent to the bot as JSON.
+		data, err := readBackup(input)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return 1
+		}
+		var encoded bytes.Buffer
+		encodeExport(&encoded, data, formatJSON)
+		input = encoded.Bytes()
+	}
+
+	output, err := sendAdminCommand(socket, append([]string{args[0]}, rest...), string(input))
+	if output != "" && !strings.HasSuffix(output, "\n") {
+		output += "\n"
+	}
+	fmt.Print(output)
+	if err != nil && err.Error() == errAdminUsage.Error() {
+		fmt.Fprintln(os.Stderr, adminUsage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
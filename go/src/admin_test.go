@@ -0,0 +1,145 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAdminCommands(t *testing.T) {
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "5"))
+	chat.command(bruno, "/yojuego "+id, "¡Hola @Bruno!")
+	chat.command(bruno, "/agregarinvitado "+id+" Tito", "Tito")
+	handlers.Wait()
+
+	list, err := runAdminCommand([]string{"games", "list"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, list, id+"\tchat "+strconv.FormatInt(chat.chat.ID, 10)+"\tpending\tsize 5\t2/10 players")
+
+	if _, err := runAdminCommand([]string{"games", "edit", id, "size=6", "date=martes 21/05", "cost=6000"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	shown, err := runAdminCommand([]string{"games", "show", id}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, shown, `"Size": "6"`, `"MaxPlayers": 12`, `"martes"`, `"Cost": 6000`)
+
+	if _, err := runAdminCommand([]string{"games", "edit", id, "size=99"}, ""); err == nil {
+		t.Error("expected an invalid size to be refused")
+	}
+	if _, err := runAdminCommand([]string{"games", "edit", id, "players=1"}, ""); err == nil {
+		t.Error("expected an unknown field to be refused")
+	}
+	if _, err := runAdminCommand([]string{"games", "edit", id, "max_players=1"}, ""); err == nil || !strings.Contains(err.Error(), "remove some first") {
+		t.Errorf("expected fewer places than people to be refused, got %v", err)
+	}
+
+	output, err := runAdminCommand([]string{"players", "remove", id, strconv.Itoa(bruno.ID)}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, output, "Their guests were removed: Tito.")
+	if game := chat.lastGame(); contains(game.Players, bruno.ID) || len(game.Guests) != 0 {
+		t.Errorf("expected Bruno and his guest to be gone, got %+v", game)
+	}
+	if _, err := runAdminCommand([]string{"players", "remove", id, strconv.Itoa(bruno.ID)}, ""); err == nil {
+		t.Error("expected removing someone not in the game to fail")
+	}
+
+	if _, err := runAdminCommand([]string{"games", "reopen", id}, ""); err == nil {
+		t.Error("expected reopening a pending game to fail")
+	}
+	chat.command(ana, "/cancelarpartido "+id, "")
+	handlers.Wait()
+	if _, err := runAdminCommand([]string{"games", "reopen", id}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !chat.lastGame().Active {
+		t.Error("expected the game to be pending again")
+	}
+
+	if _, err := runAdminCommand([]string{"games", "delete", id}, ""); err != errAdminUsage {
+		t.Errorf("expected an unknown command to be a usage error, got %v", err)
+	}
+}
+
+func TestAdminSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "admin.sock")
+	if err := os.WriteFile(socket, []byte("not a socket"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenAdminSocket(socket); err == nil {
+		t.Error("expected a file that is not a socket to be left alone")
+	}
+	os.Remove(socket)
+
+	go serveAdminSocket(socket)
+
+	var output string
+	var err error
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		if output, err = sendAdminCommand(socket, []string{"games", "show", "999999"}, ""); err == nil || !strings.Contains(err.Error(), "not answering") {
+			break
+		}
+	}
+	if err == nil || err.Error() != "there is no game 999999" {
+		t.Errorf("expected the error of the bot, got %q, %v", output, err)
+	}
+
+	if _, err := sendAdminCommand(socket, []string{"games", "list", "-all"}, ""); err != nil {
+		t.Error(err)
+	}
+	// The flags of the subcommands go through the command line too, wherever -socket is.
+	for _, args := range [][]string{
+		{"export", "-socket", socket, "-format", "csv"},
+		{"export", "-format=csv", "-chat", "-1", "-socket=" + socket},
+	} {
+		code := 0
+		output := captureStdout(t, func() { code = adminCommand(args) })
+		if code != 0 || !strings.HasPrefix(output, csvVersionLine) {
+			t.Errorf("expected %v to print a CSV export, got exit code %d and %q", args, code, output)
+		}
+	}
+	if code := adminCommand([]string{"export", "-socket"}); code != 2 {
+		t.Errorf("expected -socket without a path to be a usage error, got exit code %d", code)
+	}
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected only the owner to reach the socket, got %v, %v", info.Mode(), err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(socket))
+	if len(entries) != 1 {
+		t.Errorf("expected only the socket in its directory, got %d entries", len(entries))
+	}
+	if socket := adminSocketPath(environment(map[string]string{"FULBOT_CONFIG": writeConfigFile(t, `{"admin_socket": "/run/fulbot.sock"}`)})); socket != "/run/fulbot.sock" {
+		t.Errorf("expected the socket of the config file, got %q", socket)
+	}
+}
+
+// captureStdout returns what a function prints.
+func captureStdout(t *testing.T, run func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		contents, _ := io.ReadAll(reader)
+		output <- string(contents)
+	}()
+	run()
+	writer.Close()
+	return <-output
+}
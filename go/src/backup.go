@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Snapshots are full JSON exports, gzipped and named after the UTC time they were taken, so they sort by age. A
+// snapshot taken in the same second as another one gets a number after the time, like fulbot-20240521T220000Z-2. The
+// scheduler takes one every backups.interval_minutes into backups.directory and deletes all but the newest
+// backups.keep. A restore checks the whole snapshot before replacing the games and chats with it.
+
+const backupPrefix = "fulbot-"
+const backupSuffix = ".json.gz"
+const backupTimeLayout = "20060102T150405Z"
+
+var lastBackupMutex sync.Mutex
+var lastBackup time.Time
+
+var errEmptyBackup = errors.New("there are no games or chats to back up")
+
+// takeDueBackup takes a snapshot when backups are on and the last one is older than the interval. The games are only
+// in memory, so right after a start the bot waits a whole interval before the first one: snapshots of a store still
+// empty or barely filled would push the good ones out of the retention window, more so if the bot keeps restarting.
+func takeDueBackup(now time.Time) {
+	backups := getConfig().Backups
+	lastBackupMutex.Lock()
+	if lastBackup.IsZero() {
+		lastBackup = now
+	}
+	if backups.Directory == "" || now.Sub(lastBackup) < time.Duration(backups.IntervalMinutes)*time.Minute {
+		lastBackupMutex.Unlock()
+		return
+	}
+	lastBackup = now
+	lastBackupMutex.Unlock()
+
+	filename, err := writeBackup(backups.Directory, now)
+	if err == errEmptyBackup {
+		slog.Debug("skipping the backup of an empty store", "directory", backups.Directory)
+		return
+	}
+	if err != nil {
+		slog.Error("error taking a backup", "directory", backups.Directory, "error", err)
+		return
+	}
+	slog.Info("backup taken", "file", filename)
+	if err := pruneBackups(backups.Directory, backups.Keep); err != nil {
+		slog.Error("error deleting old backups", "directory", backups.Directory, "error", err)
+	}
+}
+
+// writeBackup saves a snapshot of every game and chat in the directory and returns its file name. It is written
+// under a temporary name first, so a crash never leaves half a snapshot behind, and linked to a name no other
+// snapshot has. An empty store is refused, its snapshot would only take the place of a useful one.
+func writeBackup(directory string, now time.Time) (string, error) {
+	data := exportData(0)
+	if len(data.Games) == 0 && len(data.Chats) == 0 {
+		return "", errEmptyBackup
+	}
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return "", err
+	}
+	temporary, err := os.CreateTemp(directory, ".backup-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(temporary.Name())
+
+	data.ExportedAt = now
+	compressed := gzip.NewWriter(temporary)
+	err = encodeExport(compressed, data, formatJSON)
+	if closeErr := compressed.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := temporary.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	// Unlike a rename, a link fails when the name is taken instead of replacing the other snapshot.
+	for sequence := 1; ; sequence++ {
+		filename := filepath.Join(directory, backupName(now, sequence))
+		err := os.Link(temporary.Name(), filename)
+		if err == nil {
+			return filename, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+	}
+}
+
+// backupName is the file name of the snapshot taken at a time, with the sequence when it is not the first that second.
+func backupName(now time.Time, sequence int) string {
+	name := backupPrefix + now.UTC().Format(backupTimeLayout)
+	if sequence > 1 {
+		name += "-" + strconv.Itoa(sequence)
+	}
+	return name + backupSuffix
+}
+
+// backupOrder splits the name of a snapshot into its time and sequence, which sort it among the others.
+func backupOrder(filename string) (string, int) {
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(filename), backupPrefix), backupSuffix)
+	stamp, number, numbered := strings.Cut(name, "-")
+	sequence := 1
+	if numbered {
+		sequence, _ = strconv.Atoi(number)
+	}
+	return stamp, sequence
+}
+
+// listBackups returns the snapshots in the directory, oldest first.
+func listBackups(directory string) ([]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupSuffix) {
+			backups = append(backups, filepath.Join(directory, name))
+		}
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		stamp, sequence := backupOrder(backups[i])
+		otherStamp, otherSequence := backupOrder(backups[j])
+		if stamp != otherStamp {
+			return stamp < otherStamp
+		}
+		return sequence < otherSequence
+	})
+	return backups, nil
+}
+
+// pruneBackups deletes all but the newest keep snapshots.
+func pruneBackups(directory string, keep int) error {
+	backups, err := listBackups(directory)
+	if err != nil {
+		return err
+	}
+	for len(backups) > keep {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// readBackup reads and checks a snapshot, compressed or not, without changing anything.
+func readBackup(contents []byte) (Export, error) {
+	if bytes.HasPrefix(contents, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(contents))
+		if err != nil {
+			return Export{}, err
+		}
+		if contents, err = io.ReadAll(reader); err != nil {
+			return Export{}, fmt.Errorf("the snapshot is damaged: %w", err)
+		}
+	}
+	data, err := decodeExport(contents)
+	if err != nil {
+		return Export{}, fmt.Errorf("the snapshot is not valid: %w", err)
+	}
+	if _, _, err := prepareImport(data); err != nil {
+		return Export{}, fmt.Errorf("the snapshot is not valid: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackups(t *testing.T) {
+	previous := getConfig()
+	defer setConfig(previous)
+	defer func(last time.Time) { lastBackup = last }(lastBackup)
+	lastBackup = time.Time{}
+
+	config := *previous
+	config.Backups = BackupConfig{Directory: filepath.Join(t.TempDir(), "backups"), IntervalMinutes: 60, Keep: 2}
+	setConfig(&config)
+	createGame(newGame(-9601, 1, "5", 10))
+
+	// The first run only starts counting, the bot may have just started with nothing in memory.
+	start := time.Date(2024, 5, 21, 20, 0, 0, 0, time.UTC)
+	for _, minutes := range []int{0, 30, 60, 90, 120} {
+		takeDueBackup(start.Add(time.Duration(minutes) * time.Minute))
+	}
+	backups, err := listBackups(config.Backups.Directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(config.Backups.Directory, "fulbot-20240521T210000Z.json.gz"),
+		filepath.Join(config.Backups.Directory, "fulbot-20240521T220000Z.json.gz"),
+	}
+	if !reflect.DeepEqual(backups, want) {
+		t.Errorf("expected an hourly snapshot keeping the newest 2, got %v", backups)
+	}
+
+	contents, err := os.ReadFile(backups[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := readBackup(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Version != exportVersion || !data.ExportedAt.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("expected a snapshot of the current version taken at 22:00, got version %d at %v", data.Version, data.ExportedAt)
+	}
+}
+
+func TestBackupsSkipAnEmptyStore(t *testing.T) {
+	previous := getConfig()
+	defer setConfig(previous)
+	defer func(last time.Time) { lastBackup = last }(lastBackup)
+	lastBackup = time.Date(2024, 5, 21, 20, 0, 0, 0, time.UTC)
+	saved := exportData(0)
+	defer restoreData(saved)
+
+	config := *previous
+	config.Backups = BackupConfig{Directory: t.TempDir(), IntervalMinutes: 60, Keep: 1}
+	setConfig(&config)
+	createGame(newGame(-9602, 1, "5", 10))
+	if _, err := writeBackup(config.Backups.Directory, lastBackup); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := restoreData(Export{Version: exportVersion}); err != nil {
+		t.Fatal(err)
+	}
+	takeDueBackup(lastBackup.Add(2 * time.Hour))
+	if _, err := runAdminCommand([]string{"backup"}, ""); err != errEmptyBackup {
+		t.Errorf("expected a manual backup of an empty store to be refused, got %v", err)
+	}
+	backups, err := listBackups(config.Backups.Directory)
+	if err != nil || len(backups) != 1 || filepath.Base(backups[0]) != "fulbot-20240521T200000Z.json.gz" {
+		t.Errorf("expected the snapshot with games to be kept, got %v, %v", backups, err)
+	}
+}
+
+func TestBackupsInTheSameSecond(t *testing.T) {
+	directory := t.TempDir()
+	now := time.Date(2024, 5, 21, 22, 0, 0, 0, time.UTC)
+	createGame(newGame(-9603, 1, "5", 10))
+	for i := 0; i < 10; i++ {
+		if _, err := writeBackup(directory, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := writeBackup(directory, now.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pruneBackups(directory, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := listBackups(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(directory, "fulbot-20240521T220000Z-9.json.gz"),
+		filepath.Join(directory, "fulbot-20240521T220000Z-10.json.gz"),
+		filepath.Join(directory, "fulbot-20240521T220001Z.json.gz"),
+	}
+	if !reflect.DeepEqual(backups, want) {
+		t.Errorf("expected every snapshot of the same second to be kept apart and sorted, got %v", backups)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	saved := exportData(0)
+	defer restoreData(saved)
+
+	chat := newTestGroup(t)
+	kept := chat.newGame(ana, "5")
+	var snapshot bytes.Buffer
+	if err := encodeExport(&snapshot, exportData(0), formatJSON); err != nil {
+		t.Fatal(err)
+	}
+	lost := chat.newGame(bruno, "6")
+	handlers.Wait()
+
+	output, err := runAdminCommand([]string{"restore"}, snapshot.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, output, "Restored ")
+	if _, exists := getGame(kept); !exists {
+		t.Errorf("expected game %d from the snapshot to be back", kept)
+	}
+	if _, exists := getGame(lost); exists {
+		t.Errorf("expected game %d, newer than the snapshot, to be gone", lost)
+	}
+
+	invalid := exportFixture()
+	invalid.Games = append(invalid.Games, invalid.Games[0])
+	var broken bytes.Buffer
+	encodeExport(&broken, invalid, formatJSON)
+	for name, contents := range map[string][]byte{
+		"duplicated game": broken.Bytes(),
+		"truncated gzip":  []byte{0x1f, 0x8b, 0x08, 0x00},
+		"not a snapshot":  []byte("hola"),
+	} {
+		if _, err := runAdminCommand([]string{"restore"}, string(contents)); err == nil {
+			t.Errorf("expected a %s to be refused", name)
+		}
+	}
+	if _, exists := getGame(kept); !exists {
+		t.Error("expected a refused restore to leave the games alone")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// BotCommand is a command as Telegram shows it in the command menu of the clients.
+type BotCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+type botCommandScope struct {
+	Type string `json:"type"`
+}
+
+// commandMenuScopes maps each Telegram scope to the command scopes listed in it.
+var commandMenuScopes = map[string][]CommandScope{
+	"all_private_chats": {ScopeAllChats, ScopePrivate},
+	"all_group_chats":   {ScopeAllChats, ScopeGroups},
+}
+
+func chatCommandScopes(chat *tgbotapi.Chat) []CommandScope {
+	if chat.IsPrivate() {
+		return commandMenuScopes["all_private_chats"]
+	}
+	return commandMenuScopes["all_group_chats"]
+}
+
+// commandMenuLanguages are the language codes the menu is published for, the empty one being the default for everyone else.
+var commandMenuLanguages = []string{"", "en", "pt"}
+
+func (command Command) visibleIn(scopes []CommandScope) bool {
+	for _, scope := range scopes {
+		if command.Scope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// buildCommandMenu derives the Telegram command menu for a scope and language from the same commands /ayuda lists.
+func buildCommandMenu(commands []Command, scopes []CommandScope, language string) []BotCommand {
+	if language == "" {
+		language = defaultLanguage
+	}
+	menu := make([]BotCommand, 0, len(commands))
+	for _, command := range commands {
+		if !command.visibleIn(scopes) {
+			continue
+		}
+		menu = append(menu, BotCommand{Command: command.name(language), Description: menuDescription(command.help(language))})
+	}
+	return menu
+}
+
+// menuDescription shortens a help text to the 256 characters Telegram allows, cutting between characters so the text
+// stays valid UTF-8.
+func menuDescription(help string) string {
+	if runes := []rune(help); len(runes) > 256 {
+		return strings.TrimSpace(string(runes[:253])) + "..."
+	}
+	return help
+}
+
+func commandMenuParams(scope string, language string) url.Values {
+	encodedScope, _ := json.Marshal(botCommandScope{Type: scope})
+	params := url.Values{}
+	params.Set("scope", string(encodedScope))
+	if language != "" {
+		params.Set("language_code", language)
+	}
+	return params
+}
+
+func getPublishedCommands(bot TelegramClient, scope string, language string) ([]BotCommand, error) {
+	resp, err := bot.MakeRequest("getMyCommands", commandMenuParams(scope, language))
+	if err != nil {
+		return nil, err
+	}
+	var published []BotCommand
+	err = json.Unmarshal(resp.Result, &published)
+	return published, err
+}
+
+// publishCommands registers the command menu for every scope and language, only calling setMyCommands when it changed.
+func publishCommands(bot TelegramClient) {
+	commands := getCommands()
+	for scope, commandScopes := range commandMenuScopes {
+		for _, language := range commandMenuLanguages {
+			menu := buildCommandMenu(commands, commandScopes, language)
+			published, err := getPublishedCommands(bot, scope, language)
+			if err == nil && reflect.DeepEqual(published, menu) {
+				continue
+			}
+
+			params := commandMenuParams(scope, language)
+			encodedMenu, _ := json.Marshal(menu)
+			params.Set("commands", string(encodedMenu))
+			if _, err := bot.MakeRequest("setMyCommands", params); err != nil {
+				slog.Error("error publishing the command menu", "scope", scope, "language", language, "error", err)
+			} else {
+				slog.Info("published the command menu", "scope", scope, "language", language, "commands", len(menu))
+			}
+		}
+	}
+}
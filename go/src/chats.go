@@ -0,0 +1,110 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// ChatSettings holds everything the bot remembers about a group chat, independently of its games.
+type ChatSettings struct {
+	ID             int64
+	Members        []int
+	PriorityWindow time.Duration
+	RecurringGames []RecurringGame
+	Venues         []Venue
+	// Language is the code of the language the bot speaks in the chat, empty for the default one.
+	Language string
+	// Templates are the message templates the chat overrides, by template name.
+	Templates map[string]string
+}
+
+// clone returns a copy of the settings that shares no slice or map with them, so it can be changed outside the mutex.
+func (chat ChatSettings) clone() ChatSettings {
+	chat.Members = append(make([]int, 0, len(chat.Members)), chat.Members...)
+	chat.Venues = append([]Venue{}, chat.Venues...)
+	recurringGames := make([]RecurringGame, 0, len(chat.RecurringGames))
+	for _, recurring := range chat.RecurringGames {
+		recurring.Address = append([]string{}, recurring.Address...)
+		recurringGames = append(recurringGames, recurring)
+	}
+	chat.RecurringGames = recurringGames
+	if chat.Templates != nil {
+		templates := make(map[string]string, len(chat.Templates))
+		for name, text := range chat.Templates {
+			templates[name] = text
+		}
+		chat.Templates = templates
+	}
+	return chat
+}
+
+var chatsMutex sync.Mutex
+var chats map[int64]ChatSettings = make(map[int64]ChatSettings)
+
+func getChatSettings(chatID int64) ChatSettings {
+	chatsMutex.Lock()
+	defer chatsMutex.Unlock()
+
+	chat, exists := chats[chatID]
+	if !exists {
+		chat = ChatSettings{ID: chatID, Members: make([]int, 0)}
+	}
+	return chat.clone()
+}
+
+func updateChatSettings(chat ChatSettings) {
+	chatsMutex.Lock()
+	defer chatsMutex.Unlock()
+	chats[chat.ID] = chat.clone()
+}
+
+// isChatAdmin tells if a user administers a group. Everybody administers their private chat with the bot, and the
+// bot administrators every chat.
+func isChatAdmin(chat *tgbotapi.Chat, userID int) bool {
+	if chat.IsPrivate() || isBotAdmin(userID) {
+		return true
+	}
+	member, err := bot.GetChatMember(tgbotapi.ChatConfigWithUser{ChatID: chat.ID, UserID: userID})
+	if err != nil {
+		slog.Error("error obtaining the status of a chat member", "chat_id", chat.ID, "user_id", userID, "error", err)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+func (chat ChatSettings) isMember(userID int) bool {
+	return contains(chat.Members, userID)
+}
+
+// Group titles are remembered from the updates too, so a player can be told in which group each of their games is.
+var chatTitles map[int64]string = make(map[int64]string)
+
+// rememberChat stores the title of the group an update comes from.
+func rememberChat(update tgbotapi.Update) {
+	var chat *tgbotapi.Chat
+	if update.Message != nil {
+		chat = update.Message.Chat
+	} else if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		chat = update.CallbackQuery.Message.Chat
+	}
+	if chat == nil || chat.IsPrivate() || chat.Title == "" {
+		return
+	}
+	chatsMutex.Lock()
+	defer chatsMutex.Unlock()
+	chatTitles[chat.ID] = chat.Title
+}
+
+// knownChatTitles returns a copy of the remembered group titles.
+func knownChatTitles() map[int64]string {
+	chatsMutex.Lock()
+	defer chatsMutex.Unlock()
+	titles := make(map[int64]string, len(chatTitles))
+	for id, title := range chatTitles {
+		titles[id] = title
+	}
+	return titles
+}
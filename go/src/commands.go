@@ -2,8 +2,6 @@ package main
 
 import (
 	"errors"
-	"fmt"
-	"log"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,22 +13,229 @@ var mutex sync.Mutex
 var games map[int]Game = make(map[int]Game)
 var nextGameId = 1
 
-type CommandHandlerFunc func(bot *tgbotapi.BotAPI, message *tgbotapi.Message)
-
-func getCommands() map[string]CommandHandlerFunc {
-	return map[string]CommandHandlerFunc{
-		"yojuego":          handleYoJuegoCommand,
-		"verpartido":       handleVerPartidoCommand,
-		"verpartidos":      handleVerPartidosCommand,
-		"nuevopartido":     handleNuevoPartidoCommand,
-		"agregardireccion": handleAgregarDireccionCommand,
-		"agregarfecha":     handleAgregarFechaCommand,
-		"agregarhorario":   handleAgregarHorarioCommand,
-		"cancelarpartido":  handleCancelarPartidoCommand,
-		"darsedebaja":      handleDarseDeBajaCommand,
-		"agregarinvitado":  handleAgregrarInvitadoCommand,
-		"bajarinvitado":    handleBajarInvitadoCommand,
-		"ayuda":            handleayudaCommand,
+type CommandHandlerFunc func(bot TelegramClient, message *tgbotapi.Message, args CommandArgs)
+
+// CallbackHandlerFunc handles inline keyboard presses, dispatched by the prefix of the callback data before ":".
+type CallbackHandlerFunc func(bot TelegramClient, query *tgbotapi.CallbackQuery)
+
+var gameArgument = Argument{Name: "numero de partido", Kind: ArgGame}
+
+// getCommands lists every command in the order they are shown by /ayuda.
+func getCommands() []Command {
+	return []Command{
+		{
+			Name:      "yojuego",
+			Names:     map[string]string{"en": "join", "pt": "eujogo"},
+			Emoji:     emojiThumbsUp,
+			Arguments: []Argument{gameArgument},
+			Handler:   handleYoJuegoCommand,
+		},
+		{
+			Name:      "verpartido",
+			Names:     map[string]string{"en": "game", "pt": "verjogo"},
+			Emoji:     emojiCalendar,
+			Arguments: []Argument{gameArgument},
+			Handler:   handleVerPartidoCommand,
+		},
+		{
+			Name:    "verpartidos",
+			Names:   map[string]string{"en": "games", "pt": "jogos"},
+			Aliases: []string{"partidos"},
+			Emoji:   emojiCalendar,
+			Handler: handleVerPartidosCommand,
+		},
+		{
+			Name:    "mispartidos",
+			Names:   map[string]string{"en": "mygames", "pt": "meusjogos"},
+			Emoji:   emojiCalendar,
+			Scope:   ScopePrivate,
+			Handler: handleMisPartidosCommand,
+		},
+		{
+			Name:      "nuevopartido",
+			Names:     map[string]string{"en": "newgame", "pt": "novojogo"},
+			Emoji:     emojiBall,
+			Arguments: []Argument{{Name: "tamaño", Kind: ArgWord, Optional: true}},
+			Handler:   handleNuevoPartidoCommand,
+		},
+		{
+			Name:      "agregarfecha",
+			Names:     map[string]string{"en": "setdate", "pt": "data"},
+			Emoji:     emojiCalendar,
+			Arguments: []Argument{gameArgument, {Name: "fecha", Kind: ArgText}},
+			Handler:   handleAgregarFechaCommand,
+		},
+		{
+			Name:      "agregarhorario",
+			Names:     map[string]string{"en": "settime", "pt": "horario"},
+			Emoji:     emojiClock,
+			Arguments: []Argument{gameArgument, {Name: "horario", Kind: ArgText}},
+			Handler:   handleAgregarHorarioCommand,
+		},
+		{
+			Name:      "agregardireccion",
+			Names:     map[string]string{"en": "setaddress", "pt": "endereco"},
+			Emoji:     emojiAddress,
+			Arguments: []Argument{gameArgument, {Name: "direccion o cancha", Kind: ArgText}},
+			Handler:   handleAgregarDireccionCommand,
+		},
+		{
+			Name:       "cancelarpartido",
+			Names:      map[string]string{"en": "cancelgame", "pt": "cancelarjogo"},
+			Emoji:      emojiCross,
+			Arguments:  []Argument{gameArgument},
+			Permission: PermissionOrganizer,
+			Handler:    handleCancelarPartidoCommand,
+		},
+		{
+			Name:      "darsedebaja",
+			Names:     map[string]string{"en": "leave", "pt": "sair"},
+			Emoji:     emojiThumbsDown,
+			Arguments: []Argument{gameArgument, {Name: "mantener", Kind: ArgWord, Optional: true}},
+			Handler:   handleDarseDeBajaCommand,
+		},
+		{
+			Name:      "agregarinvitado",
+			Names:     map[string]string{"en": "addguest", "pt": "convidado"},
+			Aliases:   []string{"agregartercero"},
+			Emoji:     emojiGhost,
+			Arguments: []Argument{gameArgument, {Name: "nombre", Kind: ArgText}},
+			Handler:   handleAgregarInvitadoCommand,
+		},
+		{
+			Name:      "bajarinvitado",
+			Names:     map[string]string{"en": "removeguest", "pt": "removerconvidado"},
+			Emoji:     emojiCross,
+			Arguments: []Argument{gameArgument, {Name: "nombre", Kind: ArgText}},
+			Handler:   handleBajarInvitadoCommand,
+		},
+		{
+			Name:       "costo",
+			Names:      map[string]string{"en": "cost", "pt": "custo"},
+			Emoji:      emojiMoney,
+			Arguments:  []Argument{gameArgument, {Name: "monto", Kind: ArgNumber}},
+			Permission: PermissionOrganizer,
+			Handler:    handleCostoCommand,
+		},
+		{
+			Name:      "pague",
+			Names:     map[string]string{"en": "paid", "pt": "paguei"},
+			Emoji:     emojiMoney,
+			Arguments: []Argument{gameArgument},
+			Handler:   handlePagueCommand,
+		},
+		{
+			Name:    "deudas",
+			Names:   map[string]string{"en": "debts", "pt": "dividas"},
+			Emoji:   emojiMoney,
+			Scope:   ScopeGroups,
+			Handler: handleDeudasCommand,
+		},
+		{
+			Name:  "socios",
+			Names: map[string]string{"en": "members"},
+			Emoji: emojiThumbsUp,
+			Arguments: []Argument{
+				{Name: "opcion", Kind: ArgWord, Optional: true},
+				{Name: "jugador", Kind: ArgUser, Optional: true},
+				{Name: "minutos", Kind: ArgNumber, Optional: true},
+			},
+			Scope:   ScopeGroups,
+			Handler: handleSociosCommand,
+		},
+		{
+			Name:  "partidofijo",
+			Names: map[string]string{"en": "weekly", "pt": "jogofixo"},
+			Emoji: emojiCalendar,
+			Arguments: []Argument{
+				{Name: "dia", Kind: ArgWord, Optional: true},
+				{Name: "horario", Kind: ArgWord, Optional: true},
+				{Name: "tamaño", Kind: ArgWord, Optional: true},
+				{Name: "direccion", Kind: ArgText, Optional: true},
+			},
+			Scope:   ScopeGroups,
+			Handler: handlePartidoFijoCommand,
+		},
+		{
+			Name:      "agregarcancha",
+			Names:     map[string]string{"en": "addvenue", "pt": "novaquadra"},
+			Emoji:     emojiAddress,
+			Arguments: []Argument{{Name: "nombre", Kind: ArgWord}, {Name: "direccion | notas", Kind: ArgText}},
+			Scope:     ScopeGroups,
+			Handler:   handleAgregarCanchaCommand,
+		},
+		{
+			Name:    "canchas",
+			Names:   map[string]string{"en": "venues", "pt": "quadras"},
+			Emoji:   emojiAddress,
+			Scope:   ScopeGroups,
+			Handler: handleCanchasCommand,
+		},
+		{
+			Name:      "borrarcancha",
+			Names:     map[string]string{"en": "removevenue", "pt": "apagarquadra"},
+			Emoji:     emojiCross,
+			Arguments: []Argument{{Name: "nombre", Kind: ArgText}},
+			Scope:     ScopeGroups,
+			Handler:   handleBorrarCanchaCommand,
+		},
+		{
+			Name:       "votarfecha",
+			Names:      map[string]string{"en": "datepoll", "pt": "votardata"},
+			Emoji:      emojiCalendar,
+			Arguments:  []Argument{gameArgument, {Name: "plazo", Kind: ArgDuration, Optional: true}, {Name: "opcion 1 | opcion 2", Kind: ArgText}},
+			Permission: PermissionOrganizer,
+			Handler:    handleVotarFechaCommand,
+		},
+		{
+			Name:       "cerrarvotacion",
+			Names:      map[string]string{"en": "closepoll", "pt": "fecharvotacao"},
+			Emoji:      emojiCross,
+			Arguments:  []Argument{gameArgument},
+			Permission: PermissionOrganizer,
+			Handler:    handleCerrarVotacionCommand,
+		},
+		{
+			Name:    "ayuda",
+			Names:   map[string]string{"en": "help", "pt": "ajuda"},
+			Aliases: []string{"start"},
+			Emoji:   emojiHelp,
+			Handler: handleayudaCommand,
+		},
+		{
+			Name:       "plantilla",
+			Names:      map[string]string{"en": "template", "pt": "modelo"},
+			Emoji:      emojiHelp,
+			Arguments:  []Argument{{Name: "nombre", Kind: ArgWord, Optional: true}, {Name: "plantilla", Kind: ArgText, Optional: true}},
+			Permission: PermissionChatAdmin,
+			Handler:    handlePlantillaCommand,
+		},
+		{
+			Name:       "exportar",
+			Names:      map[string]string{"en": "export"},
+			Emoji:      emojiHelp,
+			Arguments:  []Argument{{Name: "formato", Kind: ArgWord, Optional: true}},
+			Permission: PermissionChatAdmin,
+			Scope:      ScopeGroups,
+			Handler:    handleExportarCommand,
+		},
+		{
+			Name:       "idioma",
+			Names:      map[string]string{"en": "language"},
+			Emoji:      emojiHelp,
+			Arguments:  []Argument{{Name: "idioma", Kind: ArgWord, Optional: true}},
+			Permission: PermissionChatAdmin,
+			Handler:    handleIdiomaCommand,
+		},
+	}
+}
+
+func getCallbacks() map[string]CallbackHandlerFunc {
+	return map[string]CallbackHandlerFunc{
+		"baja":    handleLeaveButtonCallback,
+		"fecha":   handleDateVoteCallback,
+		"wizard":  handleWizardCallback,
+		"yojuego": handleJoinButtonCallback,
 	}
 }
 
@@ -41,376 +246,235 @@ func getCommands() map[string]CommandHandlerFunc {
 #                                                            #
 ##############################################################
 */
-func handleBajarInvitadoCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
+func handleBajarInvitadoCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
 	var response string
-
-	if len(params) < 1 || params[0] == "" {
-		response = fmt.Sprintf("Para dar de baja a un invitado @%s, debes proporcionar el numero del partido y el nombre. Ejemplo: /bajarinivitado \\[numero] \\[nombre]", message.From.FirstName)
+	game := args.Game
+
+	playerName := args.Text("nombre")
+	index, found := game.findGuest(playerName, message.From.ID)
+	if !found {
+		response = tr(args.Language, "guests.not_found", "guest", playerName)
+	} else if game.Guests[index].InviterID != message.From.ID && game.OrganizerID != message.From.ID {
+		response = tr(args.Language, "guests.remove_forbidden", "guest", playerName)
 	} else {
-		gameId, err := strconv.Atoi(params[0])
-		if err != nil {
-			response = params[0] + " no es un numero de partido valido."
-		} else {
-			game, exists := games[gameId]
-			if !exists || !game.Active {
-				response = fmt.Sprintf("No hay un partido pendiente con ese numero, @%s.", message.From.FirstName)
-			} else {
-				playerName := strings.Join(params[1:], " ")
-				if !containsString(game.Guests, playerName) {
-					response = fmt.Sprintf("No es posible dar de baja a %s. No se encuentra en el partido.", playerName)
-				} else {
-					game.Guests = removeString(game.Guests, playerName)
-					updateGame(gameId, game)
-					response = fmt.Sprintf("@%s diste de baja a %s.", message.From.FirstName, playerName)
-				}
-			}
-		}
-
+		game.Guests = removeGuestAt(game.Guests, index)
+		updateGame(game.Id, game)
+		response = tr(args.Language, "guests.removed", "name", message.From.FirstName, "guest", playerName)
 	}
 
 	respondToMessage(message, response)
 }
-func handleAgregrarInvitadoCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
-	var response string
 
-	if len(params) < 1 || params[0] == "" {
-		response = fmt.Sprintf("Para agregar a un tercero @%s, debes proporcionar el numero del partido y el nombre del jugador. Ejemplo: /agregarinvitado [numero] [nombre]", message.From.FirstName)
+func handleAgregarInvitadoCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	var response string
+	game := args.Game
+
+	playerName := args.Text("nombre")
+	if game.hasGuestNamed(playerName) || game.hasWaitingGuestNamed(playerName) {
+		response = tr(args.Language, "guests.duplicate", "guest", playerName, "name", message.From.FirstName)
+	} else if len(game.guestsInvitedBy(message.From.ID))+game.waitingGuestsInvitedBy(message.From.ID) >= maxGuestsPerPlayer {
+		response = trn(args.Language, "guests.limit", maxGuestsPerPlayer, "name", message.From.FirstName)
+	} else if game.inPriorityWindow(clock()) {
+		game.Waitlist = append(game.Waitlist, WaitlistEntry{PlayerID: message.From.ID, GuestName: playerName})
+		updateGame(game.Id, game)
+		response = tr(args.Language, "guests.waitlisted", "name", message.From.FirstName, "time", game.PriorityUntil.Format("15:04"), "guest", playerName)
+	} else if !game.isFull() {
+		game.Guests = append(game.Guests, Guest{Name: playerName, InviterID: message.From.ID})
+		updateGame(game.Id, game)
+		response = tr(args.Language, "guests.added", "name", message.From.FirstName, "guest", playerName)
 	} else {
-		gameId, err := strconv.Atoi(params[0])
-		if err != nil {
-			response = params[0] + "no es un numero de partido valido"
-		} else {
-			game, exists := games[gameId]
-			if !exists || !game.Active {
-				response = fmt.Sprintf("No hay un partido pendiente con ese numero, @%s.", message.From.FirstName)
-			} else if len(params) < 2 || params[1] == "" {
-				response = fmt.Sprintf("@%s debes agregar el nombre del jugador! Ejemplo: /agregartercero [numero] [nombre]", message.From.FirstName)
-			} else {
-				playerName := strings.Join(params[1:], " ")
-				if game.MaxPlayers > (len(game.Players) + len(game.Guests)) {
-					game.Guests = append(game.Guests, playerName)
-					updateGame(gameId, game)
-					response = fmt.Sprintf("@%s has invitado a %s al partido .", message.From.FirstName, playerName)
-
-				} else {
-					response = fmt.Sprintf("El partido esta completo @%s, no podes invitar a %s", message.From.FirstName, playerName)
-				}
-
-			}
-		}
+		response = tr(args.Language, "guests.full", "name", message.From.FirstName, "guest", playerName)
 	}
+
 	respondToMessage(message, response)
 }
 
-func handleDarseDeBajaCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
+func handleDarseDeBajaCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
 	var response string
-
-	if len(params) < 1 || params[0] == "" {
-		response = fmt.Sprintf("Para darse de baja de un partido @%s, debes proporcionar el numero del partido. Ejemplo: /darsedebaja [numero]", message.From.FirstName)
+	game := args.Game
+
+	playerId := message.From.ID
+	if game.isWaiting(playerId) {
+		removePlayer(&game, playerId, false)
+		updateGame(game.Id, game)
+		response = tr(args.Language, "leave.waitlist", "name", message.From.FirstName)
+	} else if !contains(game.Players, playerId) {
+		response = tr(args.Language, "leave.not_playing", "name", message.From.FirstName)
 	} else {
-		gameId, err := strconv.Atoi(params[0])
-		if err != nil {
-			response = params[0] + " no es un numero de partido valido."
-		} else {
-			game, exists := games[gameId]
-			if !exists || !game.Active {
-				response = fmt.Sprintf("No hay un partido pendiente con ese numero, @%s.", message.From.FirstName)
+		response = tr(args.Language, "leave.done", "name", message.From.FirstName)
+		keepGuests := isOption(args.Language, args.Text("mantener"), "option.keep")
+		guests := removePlayer(&game, playerId, keepGuests)
+		if len(guests) > 0 {
+			if keepGuests && game.OrganizerID != playerId {
+				response += " " + tr(args.Language, "leave.guests_kept", "guests", joinGuestNames(guests))
 			} else {
-				playerId := message.From.ID
-				if !contains(game.Players, playerId) {
-					response = fmt.Sprintf("No es posible darse de baja, @%s. No te encontras en el partido.", message.From.FirstName)
-				} else {
-					game.Players = remove(game.Players, playerId)
-					updateGame(gameId, game)
-					response = fmt.Sprintf("Te has dado de baja, @%s.", message.From.FirstName)
-				}
+				response += " " + tr(args.Language, "leave.guests_removed", "guests", joinGuestNames(guests))
 			}
 		}
-
+		updateGame(game.Id, game)
 	}
 
 	respondToMessage(message, response)
 }
 
-func handleYoJuegoCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
-	var response string
-
-	if len(params) < 1 || params[0] == "" {
-		response = fmt.Sprintf("Para sumarte a un partido @%s, debes proporcionar el numero del partido. Ejemplo: /yojuego [numero]", message.From.FirstName)
-	} else {
-		gameId, err := strconv.Atoi(params[0])
-		if err != nil {
-			response = params[0] + " no es un numero de partido valido."
-		} else {
-			game, exists := games[gameId]
-			if !exists || !game.Active {
-				response = fmt.Sprintf("No hay un partido pendiente con ese numero, @%s. Puedes iniciar uno nuevo con /nuevopartido", message.From.FirstName)
-			} else {
-				if game.MaxPlayers > (len(game.Players) + len(game.Guests)) {
-					playerID := message.From.ID
-					if !contains(game.Players, playerID) {
-						game.Players = append(game.Players, playerID)
-						updateGame(gameId, game)
-						response = fmt.Sprintf("¡Hola @%s! Te has unido al partido. ¡Buena suerte!", message.From.FirstName)
-					} else {
-						response = fmt.Sprintf("Ya estás en el partido @%s. ¡A jugar!", message.From.FirstName)
-					}
-				} else {
-					response = fmt.Sprintf("El partido esta completo @%s, no te podes anotar", message.From.FirstName)
-				}
-			}
+// removePlayer takes a player out of a game and its waitlist, along with the guests they invited, who stay under the
+// organizer when keepGuests is set and the player is not the organizer. It returns the guests the player had invited.
+func removePlayer(game *Game, playerID int, keepGuests bool) []Guest {
+	keepGuests = keepGuests && game.OrganizerID != playerID
+	game.Players = remove(game.Players, playerID)
+
+	waitlist := make([]WaitlistEntry, 0, len(game.Waitlist))
+	for _, entry := range game.Waitlist {
+		if entry.PlayerID != playerID {
+			waitlist = append(waitlist, entry)
+		} else if keepGuests && entry.isGuest() {
+			entry.PlayerID = game.OrganizerID
+			waitlist = append(waitlist, entry)
+		}
+	}
+	game.Waitlist = waitlist
+
+	guests := game.guestsInvitedBy(playerID)
+	remaining := make([]Guest, 0, len(game.Guests))
+	for _, guest := range game.Guests {
+		if guest.InviterID != playerID {
+			remaining = append(remaining, guest)
+		} else if keepGuests {
+			guest.InviterID = game.OrganizerID
+			remaining = append(remaining, guest)
 		}
 	}
+	game.Guests = remaining
+	return guests
+}
 
-	respondToMessage(message, response)
+func handleYoJuegoCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	respondToMessage(message, joinGame(args.Game, message.From))
+}
+
+func handleVerPartidoCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	data := TemplateData{Game: gameTemplateData(args.Game, true), User: HTML(escapeHTML(message.From.FirstName))}
+	respondToMessage(message, renderTemplate(message.Chat.ID, templateRoster, data))
+	sendVenue(args.Game)
 }
 
-func handleVerPartidoCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
+func handleVerPartidosCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	data := TemplateData{Games: pendingGamesTemplateData(args.Language), User: HTML(escapeHTML(message.From.FirstName))}
+	respondToMessage(message, renderTemplate(message.Chat.ID, templateList, data))
+}
+
+func handleNuevoPartidoCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
 	var response string
 
-	if len(params) < 1 || params[0] == "" {
-		response = fmt.Sprintf("Para ver un partido @%s, debes proporcionar el numero del mismo. Ejemplo: /verpartido [numero]", message.From.FirstName)
-	} else {
-		gameId, err := strconv.Atoi(params[0])
-		if err != nil {
-			response = params[0] + " no es un numero de partido valido."
-		} else {
-			game, exists := games[gameId]
-			if !exists || !game.Active {
-				response = fmt.Sprintf("No hay un partido pendiente con ese numero, @%s. Puedes iniciar uno nuevo con /nuevopartido", message.From.FirstName)
-			} else {
+	if !args.Has("tamaño") {
+		startGameWizard(bot, message)
+		return
+	}
 
-				response = "Partido " + strconv.Itoa(gameId) + ":\n"
-
-				if game.Date != nil {
-					response += "\n    - Fecha: " + strings.Join(game.Date, " ")
-				}
-				if game.Schedule != nil {
-					response += "\n    - Horario: " + strings.Join(game.Schedule, " ")
-				}
-				if game.Address != nil {
-					response += "\n    - Direccion: " + strings.Join(game.Address, " ")
-				}
-				response += "\n" + "Jugadores:" + "\n"
-				countPlayers := 0
-				for _, playerID := range game.Players {
-					user := getUserInfo(bot, message.Chat.ID, playerID)
-					if user != nil {
-						countPlayers++
-						response += strconv.Itoa(countPlayers) + ". " + user.FirstName + " " + user.LastName + "\n"
-
-					}
-
-				}
-				for _, playerName := range game.Guests {
-					countPlayers++
-					response += strconv.Itoa(countPlayers) + ". " + playerName + "\n"
-
-				}
-				response += "\nTotal de jugadores: " + strconv.Itoa(countPlayers) + "/" + strconv.Itoa(game.MaxPlayers)
-			}
-		}
+	size := args.Text("tamaño")
+	maxPlayers, err := getMaxPlayersByTamano(size)
+	if err != nil {
+		response = tr(args.Language, "newgame.error", "error", translateError(args.Language, err))
+	} else {
+		game := createGame(newGame(message.Chat.ID, message.From.ID, size, maxPlayers))
+		response = renderTemplate(message.Chat.ID, templateCreation, TemplateData{Game: gameTemplateData(game, false), User: HTML(escapeHTML(message.From.FirstName))})
 	}
 	respondToMessage(message, response)
 }
 
-func handleVerPartidosCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	var response string
-
-	if len(games) < 1 {
-		response = fmt.Sprintf("No hay partidos pendientes, @%s. Puedes iniciar uno nuevo con /nuevopartido", message.From.FirstName)
-	} else {
-		response = "Proximos partidos:" + "\n\n"
-		var activeGamesTotal = 0
-		for _, game := range games {
-			if !game.Active {
-				continue
-			}
-			convertedID := strconv.Itoa(game.Id)
-			playerCount := strconv.Itoa(len(game.Players)) + "/" + strconv.Itoa(game.MaxPlayers)
+func handleAgregarDireccionCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	game := args.Game
 
-			response += unicodeBulletPoint + " Partido " + convertedID + ", Jugadores: " + playerCount
-			if game.Date != nil {
-				response += "\n    - Fecha: " + strings.Join(game.Date, " ")
-			}
-			if game.Schedule != nil {
-				response += "\n    - Horario: " + strings.Join(game.Schedule, " ")
-			}
-			if game.Address != nil {
-				response += "\n    - Direccion: " + strings.Join(game.Address, " ")
-			}
-			response += "\n"
-			activeGamesTotal++
-		}
-		response += "Total de partidos: " + strconv.Itoa(activeGamesTotal) + ".\n\n"
-		response += "Puedes usar /verpartido [numero de partido] para mas inforamcion."
+	applyVenue(&game, args.Words("direccion o cancha"))
+	updateGame(game.Id, game)
+	response := tr(args.Language, "address.added", "game", game.Id)
+	if game.Venue != "" {
+		response = tr(args.Language, "address.venue_added", "venue", game.Venue, "game", game.Id)
 	}
-
 	respondToMessage(message, response)
 }
 
-func handleNuevoPartidoCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
-	var response string
+func handleAgregarHorarioCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	game := args.Game
 
-	if len(params) < 1 {
-		response = fmt.Sprintf("Para iniciar un nuevo partido @%s, debes proporcionar el tamaño del partido. Ejemplo: /nuevopartido [tamaño]", message.From.FirstName)
-	} else {
-		size := params[0]
-		maxPlayers, err := getMaxPlayersByTamano(size)
-		if err != nil {
-			response = "Error al crear nuevo partido: " + err.Error()
-		} else {
-			game := Game{
-				Id:          nextGameId,
-				Active:      true,
-				Players:     make([]int, 0),
-				Guests:      make([]string, 0),
-				OrganizerID: message.From.ID,
-				Size:        size,
-				MaxPlayers:  maxPlayers,
-			}
-			updateGame(nextGameId, game)
-			response = "Se ha iniciado un nuevo partido de " + size + ". Puedes unirte al partido con el comando /yojuego " + strconv.Itoa(nextGameId)
-		}
+	game.Schedule = args.Words("horario")
+	updateGame(game.Id, game)
+	respondToMessage(message, tr(args.Language, "schedule.added", "game", game.Id))
+}
 
-	}
-	nextGameId++
-	respondToMessage(message, response)
+func handleAgregarFechaCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	game := args.Game
+
+	game.Date = args.Words("fecha")
+	updateGame(game.Id, game)
+	respondToMessage(message, tr(args.Language, "date.added", "game", game.Id))
 }
 
-func handleAgregarDireccionCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
-	var response string
+func handleCancelarPartidoCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	game := args.Game
 
-	if len(params) < 1 || params[0] == "" {
-		response = fmt.Sprintf("Para modificar un partido @%s, debes proporcionar el numero del mismo. Ejemplo: /agregardireccion [numero] [direccion]", message.From.FirstName)
-	} else {
-		gameId, err := strconv.Atoi(params[0])
-		if err != nil {
-			response = params[0] + " no es un numero de partido valido."
-		} else {
-			game, exists := games[gameId]
-			if !exists || !game.Active {
-				response = fmt.Sprintf("No hay un partido pendiente con ese numero, @%s. Puedes iniciar uno nuevo con /nuevopartido", message.From.FirstName)
-			} else if len(params) < 2 || params[1] == "" {
-				response = fmt.Sprintf("@%s debes agregar una direccion!  Ejemplo: /agregardireccion [numero de partido] [direccion]", message.From.FirstName)
-			} else {
-				address := params[1:]
-				game.Address = address
-				updateGame(gameId, game)
-				response = "Se ha agregado la dirección al partido " + strconv.Itoa(gameId) + "."
-			}
-		}
-	}
-	respondToMessage(message, response)
+	game.Active = false
+	updateGame(game.Id, game)
+	gameLogger(game).Info("game cancelled", "user_id", message.From.ID)
+	respondToMessage(message, tr(args.Language, "game.cancelled", "name", message.From.FirstName))
 }
 
-func handleAgregarHorarioCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
-	var response string
+func handleCostoCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	game := args.Game
 
-	if len(params) < 1 || params[0] == "" {
-		response = fmt.Sprintf("Para modificar un partido @%s, debes proporcionar el numero del mismo. Ejemplo: /agregarhorario [numero] [horario]", message.From.FirstName)
-	} else {
-		gameId, err := strconv.Atoi(params[0])
-		if err != nil {
-			response = params[0] + " no es un numero de partido valido."
-		} else {
-			game, exists := games[gameId]
-			if !exists || !game.Active {
-				response = fmt.Sprintf("No hay un partido pendiente con ese numero, @%s. Puedes iniciar uno nuevo con /nuevopartido", message.From.FirstName)
-			} else if len(params) < 2 || params[1] == "" {
-				response = fmt.Sprintf("@%s debes agregar un horario!  Ejemplo: /agregarhorario [numero de partido] [horario]", message.From.FirstName)
-			} else {
-				schedule := params[1:]
-				game.Schedule = schedule
-				updateGame(gameId, game)
-				response = "Se ha agregado el horario al partido " + strconv.Itoa(gameId) + "."
-			}
-		}
+	game.Cost = args.Number("monto")
+	updateGame(game.Id, game)
+	response := tr(args.Language, "cost.set", "cost", formatAmount(game.Cost), "game", game.Id)
+	if game.share() > 0 {
+		response += " " + tr(args.Language, "cost.share", "share", formatAmount(game.share()))
 	}
 	respondToMessage(message, response)
 }
 
-func handleAgregarFechaCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
+func handlePagueCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
 	var response string
-
-	if len(params) < 1 || params[0] == "" {
-		response = fmt.Sprintf("Para modificar un partido @%s, debes proporcionar el numero del mismo. Ejemplo: /agregarfecha [numero] [fecha]", message.From.FirstName)
+	game := args.Game
+
+	playerID := message.From.ID
+	if game.Cost <= 0 {
+		response = tr(args.Language, "pay.no_cost", "game", game.Id, "name", message.From.FirstName)
+	} else if game.chargedShares(playerID) == 0 {
+		response = tr(args.Language, "pay.nothing", "name", message.From.FirstName)
+	} else if game.amountOwedBy(playerID) == 0 {
+		response = tr(args.Language, "pay.already", "name", message.From.FirstName)
 	} else {
-		gameId, err := strconv.Atoi(params[0])
-		if err != nil {
-			response = params[0] + " no es un numero de partido valido."
-		} else {
-			game, exists := games[gameId]
-			if !exists || !game.Active {
-				response = fmt.Sprintf("No hay un partido pendiente con ese numero, @%s. Puedes iniciar uno nuevo con /nuevopartido", message.From.FirstName)
-			} else if len(params) < 2 || params[1] == "" {
-				response = fmt.Sprintf("@%s debes agregar una fecha!  Ejemplo: /agregarfecha [numero de partido] [fecha]", message.From.FirstName)
-			} else {
-				date := params[1:]
-				game.Date = date
-				updateGame(gameId, game)
-				response = "Se ha agregado la fecha al partido " + strconv.Itoa(gameId) + "."
-			}
-		}
+		amount := game.amountOwedBy(playerID)
+		game.Payments = append(game.Payments, Payment{PlayerID: playerID, Amount: amount})
+		updateGame(game.Id, game)
+		response = tr(args.Language, "pay.done", "name", message.From.FirstName, "amount", formatAmount(amount))
 	}
 	respondToMessage(message, response)
 }
 
-func handleCancelarPartidoCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	params := getCommandParams(message)
+func handleDeudasCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
 	var response string
 
-	if len(params) < 1 || params[0] == "" {
-		response = fmt.Sprintf("Para cancelar un partido @%s, debes proporcionar el numero del mismo. Ejemplo: /cancelarpartido [numero] [horario]", message.From.FirstName)
+	balances := getChatBalances(message.Chat.ID)
+	if len(balances) < 1 {
+		response = tr(args.Language, "debts.none") + " " + emojiThumbsUp
 	} else {
-		gameId, err := strconv.Atoi(params[0])
-		if err != nil {
-			response = params[0] + " no es un numero de partido valido."
-		} else {
-			game, exists := games[gameId]
-			if !exists || !game.Active {
-				response = fmt.Sprintf("No hay un partido pendiente con ese numero, @%s.", message.From.FirstName)
-			} else if game.OrganizerID != message.From.ID {
-				response = "Solo el organizador del partido puede cancelarlo."
-			} else {
-				game.Active = false
-				updateGame(gameId, game)
-				response = fmt.Sprintf("El partido ha sido cancelado por  @%s.", message.From.FirstName)
-			}
+		response = tr(args.Language, "debts.title") + "\n\n"
+		total := 0
+		for _, playerID := range sortedBalanceIDs(balances) {
+			response += unicodeBulletPoint + " " + escapeHTML(getPlayerName(bot, message.Chat.ID, playerID)) + ": " + formatAmount(balances[playerID]) + "\n"
+			total += balances[playerID]
 		}
+		response += "\n" + tr(args.Language, "debts.total", "amount", formatAmount(total))
 	}
 	respondToMessage(message, response)
 }
 
-func handleayudaCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	response := "Los comandos disponibles son:\n\n"
-	response += emojiThumbsUp + " /yojuego \\[numero de partido] - Únete a un partido\n"
-	response += emojiCalendar + " /verpartido \\[numero de partido] - Muestra la información de un partido\n"
-	response += emojiCalendar + " /verpartidos - Muestra la información de todos los partidos\n"
-	response += emojiBall + " /nuevopartido \\[tamaño] - Inicia un nuevo partido\n"
-	response += emojiCalendar + " /agregarfecha \\[numero de partido] \\[fecha] - Agrega la fecha a un partido\n"
-	response += emojiClock + " /agregarhorario \\[numero de partido] \\[horario] - Agrega un horario a un partido\n"
-	response += emojiAddress + " /agregardireccion \\[numero de partido] \\[direccion] - Agrega una dirección a un partido\n"
-	response += emojiCross + " /cancelarpartido \\[numero de partido] -  Cancela un partido, solo la persona que lo creo puede cancelarlo\n"
-	response += emojiThumbsDown + " /darsedebaja \\[numero de partido] - Para bajarte de un partido \n"
-	response += emojiGhost + " /agregarinvitado \\[numero de partido] \\[nombre] - Para agregar a un invitado a un partido \n"
-	response += emojiCross + " /bajarinvitado \\[numero de partido] \\[nombre] - Para dar de baja a un invitado de un partido \n"
-	response += emojiHelp + " /ayuda - Muestra la lista de comandos disponibles"
-	respondToMessage(message, response)
+func handleayudaCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	respondToMessage(message, helpText(getCommands(), chatCommandScopes(message.Chat), args.Language))
 }
 
-func handleUnknownCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
-	response := "Comando desconocido. Usa /ayuda para ver la lista de comandos disponibles."
-	respondToMessage(message, response)
+func handleUnknownCommand(bot TelegramClient, message *tgbotapi.Message) {
+	respondToMessage(message, tr(chatLanguage(message.Chat.ID), "command.unknown"))
 }
 
 /*
@@ -421,29 +485,25 @@ func handleUnknownCommand(bot *tgbotapi.BotAPI, message *tgbotapi.Message) {
 ##############################################################
 */
 
-func getUserInfo(bot *tgbotapi.BotAPI, chatID int64, userID int) *tgbotapi.User {
-	userConfig := tgbotapi.ChatConfigWithUser{
-		ChatID: chatID,
-		UserID: userID,
+// getPlayerName returns the name to show for a player, falling back to their ID when Telegram cannot resolve it.
+func getPlayerName(bot TelegramClient, chatID int64, userID int) string {
+	user := getUserInfo(bot, chatID, userID)
+	if user == nil {
+		return tr(chatLanguage(chatID), "player.unknown", "id", userID)
 	}
-	user, err := bot.GetChatMember(userConfig)
-	if err != nil {
-		log.Printf("Error obtaining user info for: %v", err)
-		return nil
-	}
-	return user.User
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
 }
 
-func contains(slice []int, item int) bool {
-	for _, i := range slice {
-		if i == item {
-			return true
-		}
+// getPlayerHandle returns the @username of a player when they have one, or their name otherwise.
+func getPlayerHandle(bot TelegramClient, chatID int64, userID int) string {
+	user := getUserInfo(bot, chatID, userID)
+	if user != nil && user.UserName != "" {
+		return "@" + user.UserName
 	}
-	return false
+	return getPlayerName(bot, chatID, userID)
 }
 
-func containsString(slice []string, item string) bool {
+func contains(slice []int, item int) bool {
 	for _, i := range slice {
 		if i == item {
 			return true
@@ -452,19 +512,15 @@ func containsString(slice []string, item string) bool {
 	return false
 }
 
-func removeString(slice []string, value string) []string {
-	index := -1
-	for i, v := range slice {
-		if v == value {
-			index = i
-			break
-		}
+func joinGuestNames(guests []Guest) string {
+	names := make([]string, 0, len(guests))
+	for _, guest := range guests {
+		names = append(names, guest.Name)
 	}
-	if index == -1 {
-		return slice
-	}
-	return append(slice[:index], slice[index+1:]...)
+	return strings.Join(names, ", ")
 }
+
+// remove returns a new slice without the first occurrence of value, leaving alone the array other copies share.
 func remove(slice []int, value int) []int {
 	index := -1
 	for i, v := range slice {
@@ -476,34 +532,104 @@ func remove(slice []int, value int) []int {
 	if index == -1 {
 		return slice
 	}
-	return append(slice[:index], slice[index+1:]...)
+	remaining := make([]int, 0, len(slice)-1)
+	remaining = append(remaining, slice[:index]...)
+	return append(remaining, slice[index+1:]...)
 }
 
+var errInvalidSize = errors.New("invalid game size")
+
 func getMaxPlayersByTamano(tamano string) (int, error) {
 	maxPlayers, err := strconv.Atoi(tamano)
-	if err != nil || maxPlayers < 1 || maxPlayers > 15 {
-		return 0, errors.New("El tamaño especificado no es válido. Debe ser un número entre 1 y 15.")
+	if err != nil || maxPlayers < 1 || maxPlayers > getConfig().MaxGameSize {
+		return 0, errInvalidSize
 	}
 	return maxPlayers * 2, nil
 }
 
-func getCommandParams(message *tgbotapi.Message) []string {
-	return strings.Split(message.CommandArguments(), " ")
-}
-
+// respondToMessage replies with an HTML message, see render.go.
 func respondToMessage(originalMessage *tgbotapi.Message, messageToSend string) {
 	if len(messageToSend) < 1 || messageToSend == "" {
-		messageToSend = "Lo siento, ocurrio un error al intentar procesar el comando."
+		messageToSend = tr(chatLanguage(originalMessage.Chat.ID), "command.error")
 	}
 
 	msg := tgbotapi.NewMessage(originalMessage.Chat.ID, messageToSend)
 	msg.ReplyToMessageID = originalMessage.MessageID
-	msg.ParseMode = "Markdown"
-	bot.Send(msg)
+	sendText(msg)
 }
-func updateGame(gameId int, game Game) {
+
+// sendMessage posts a message to a chat without replying to anyone, for notifications the bot sends on its own.
+func sendMessage(chatID int64, messageToSend string) {
+	sendText(tgbotapi.NewMessage(chatID, messageToSend))
+}
+
+// joinGame signs a user up for a game, or puts them in the waitlist during the members priority window.
+func joinGame(game Game, user *tgbotapi.User) string {
+	var response string
+	language := chatLanguage(game.ChatID)
+	playerID := user.ID
+	if game.inPriorityWindow(clock()) && !getChatSettings(game.ChatID).isMember(playerID) && !contains(game.Players, playerID) {
+		if game.isWaiting(playerID) {
+			response = tr(language, "join.already_waiting", "name", user.FirstName)
+		} else {
+			game.Waitlist = append(game.Waitlist, WaitlistEntry{PlayerID: playerID})
+			updateGame(game.Id, game)
+			response = tr(language, "join.waitlisted", "name", user.FirstName, "time", game.PriorityUntil.Format("15:04"))
+		}
+	} else if !game.isFull() {
+		if !contains(game.Players, playerID) {
+			game.Players = append(game.Players, playerID)
+			updateGame(game.Id, game)
+			response = tr(language, "join.joined", "name", user.FirstName)
+		} else {
+			response = tr(language, "join.already", "name", user.FirstName)
+		}
+	} else {
+		response = tr(language, "join.full", "name", user.FirstName)
+	}
+	return response
+}
+
+// newGame builds an empty game for a chat, opening the members priority window when the chat has one.
+func newGame(chatID int64, organizerID int, size string, maxPlayers int) Game {
+	game := Game{
+		Active:      true,
+		Players:     make([]int, 0),
+		Guests:      make([]Guest, 0),
+		OrganizerID: organizerID,
+		Size:        size,
+		MaxPlayers:  maxPlayers,
+		ChatID:      chatID,
+		Payments:    make([]Payment, 0),
+		Waitlist:    make([]WaitlistEntry, 0),
+	}
+	chat := getChatSettings(chatID)
+	if chat.PriorityWindow > 0 && len(chat.Members) > 0 {
+		game.PriorityUntil = clock().Add(chat.PriorityWindow)
+	}
+	return game
+}
+
+// createGame assigns the next game number to the game and stores it.
+func createGame(game Game) Game {
+	mutex.Lock()
+	defer mutex.Unlock()
+	game.Id = nextGameId
+	nextGameId++
+	games[game.Id] = game.clone()
+	gameLogger(game).Info("game created", "size", game.Size, "organizer_id", game.OrganizerID)
+	return game
+}
+
+func getGame(gameId int) (Game, bool) {
 	mutex.Lock()
 	defer mutex.Unlock()
-	games[gameId] = game
+	game, exists := games[gameId]
+	return game.clone(), exists
+}
 
+func updateGame(gameId int, game Game) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	games[gameId] = game.clone()
 }
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestNuevoPartido(t *testing.T) {
+	chat := newTestGroup(t)
+
+	id := chat.newGame(ana, "5")
+	game := chat.lastGame()
+	if game.MaxPlayers != 10 || game.OrganizerID != ana.ID || !game.Active {
+		t.Errorf("unexpected game %+v", game)
+	}
+	if id != game.Id {
+		t.Errorf("expected game %d, got %d", game.Id, id)
+	}
+
+	chat.command(ana, "/nuevopartido 20", "Error al crear nuevo partido", "El tamaño especificado no es válido")
+	chat.command(ana, "/newgame abc", "El tamaño especificado no es válido")
+}
+
+func TestNuevoPartidoWizard(t *testing.T) {
+	chat := newTestGroup(t)
+
+	chat.send(ana, "/nuevopartido")
+	prompt := chat.expect()
+	assertContains(t, prompt.text(), "¿de cuantos jugadores por equipo es el partido?")
+	assertContains(t, prompt.Params.Get("reply_markup"), `"text":"5","callback_data":"wizard:o:0"`, "wizard:cancel")
+
+	chat.press(bruno, prompt, "wizard:o:0")
+	if answer := chat.expect(); answer.Params.Get("text") != "Solo quien esta creando el partido puede elegir." {
+		t.Errorf("unexpected callback answer %q", answer.text())
+	}
+
+	chat.press(ana, prompt, "wizard:o:0")
+	chat.expect()
+	prompt = chat.expect()
+	assertContains(t, prompt.text(), "¿que dia se juega?")
+
+	// Only replies to the prompt are answers, the rest is the group talking.
+	chat.send(ana, "sabado 24/10")
+	fake.expectSilence(t)
+	chat.reply(ana, &tgbotapi.Message{MessageID: prompt.MessageID}, "sabado 24/10")
+	prompt = chat.expect()
+	assertContains(t, prompt.text(), "¿a que hora?")
+
+	chat.reply(ana, &tgbotapi.Message{MessageID: prompt.MessageID}, "tarde")
+	assertContains(t, chat.expect().text(), "tarde no es un horario valido.")
+	prompt = chat.expect()
+	assertContains(t, prompt.text(), "¿a que hora?")
+
+	chat.reply(ana, &tgbotapi.Message{MessageID: prompt.MessageID}, "21")
+	prompt = chat.expect()
+	assertContains(t, prompt.text(), "¿donde se juega?")
+
+	chat.press(ana, prompt, "wizard:skip")
+	chat.expect()
+	created := chat.expect()
+	assertContains(t, created.text(), "Se ha iniciado un nuevo partido de 5", "sabado 24/10", "21:00")
+
+	game := chat.lastGame()
+	chat.press(bruno, created, "yojuego:"+strconv.Itoa(game.Id))
+	chat.expect()
+	assertContains(t, chat.expect().text(), "¡Hola @Bruno! Te has unido al partido.")
+}
+
+func TestNuevoPartidoWizardLongVenue(t *testing.T) {
+	chat := newTestGroup(t)
+	venue := "ComplejoDeportivoMunicipalDelBarrioNorteConCanchasTechadasYVestuarios"
+	chat.command(ana, "/agregarcancha "+venue+" Calle 123", "Se guardo la cancha "+venue)
+
+	chat.send(ana, "/nuevopartido")
+	prompt := chat.expect()
+	for _, step := range []string{"wizard:o:0", "wizard:o:0", "wizard:o:0"} {
+		chat.press(ana, prompt, step)
+		chat.expect()
+		prompt = chat.expect()
+	}
+	assertContains(t, prompt.Params.Get("reply_markup"), `"text":"`+venue+`","callback_data":"wizard:o:0"`)
+	chat.press(ana, prompt, "wizard:o:0")
+	chat.expect()
+	assertContains(t, chat.expect().text(), "Se ha iniciado un nuevo partido de 5", venue)
+}
+
+func TestNuevoPartidoWizardCancel(t *testing.T) {
+	chat := newTestGroup(t)
+
+	chat.send(ana, "/nuevopartido")
+	prompt := chat.expect()
+	chat.press(ana, prompt, "wizard:cancel")
+	chat.expect()
+	assertContains(t, chat.expect().text(), "Se cancelo la creacion del partido, @Ana.")
+
+	chat.send(ana, "5")
+	fake.expectSilence(t)
+}
+
+func TestYoJuego(t *testing.T) {
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "1"))
+
+	chat.command(ana, "/yojuego "+id, "¡Hola @Ana! Te has unido al partido.")
+	chat.command(ana, "/yojuego "+id, "Ya estás en el partido @Ana.")
+	chat.command(bruno, "/join "+id, "¡Hola @Bruno!")
+	chat.command(carla, "/yojuego "+id, "El partido esta completo @Carla")
+
+	chat.command(ana, "/yojuego", "para usar /yojuego debes proporcionar numero de partido", "/yojuego [numero de partido]")
+	chat.command(ana, "/yojuego abc", "abc no es un numero de partido valido.")
+	chat.command(ana, "/yojuego 999999", "No hay un partido pendiente con ese numero, @Ana.")
+}
+
+func TestVerPartido(t *testing.T) {
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "5"))
+
+	chat.command(ana, "/agregarfecha "+id+" sabado 24/10", "Se ha agregado la fecha al partido "+id+".")
+	chat.command(ana, "/agregarhorario "+id+" 21:00", "Se ha agregado el horario al partido "+id+".")
+	chat.command(ana, "/agregardireccion "+id+" Av. Siempre Viva 742", "Se ha agregado la dirección al partido "+id+".")
+	chat.command(ana, "/yojuego "+id)
+	chat.command(carla, "/yojuego "+id)
+	chat.command(carla, "/agregarinvitado "+id+" Pedro <el 9>")
+
+	roster := chat.command(bruno, "/verpartido "+id,
+		"Partido "+id+":", "sabado 24/10", "21:00", "Av. Siempre Viva 742",
+		"1. Ana", "2. Carla Paz", "3. Pedro &lt;el 9&gt; (invitado de @carla)", "Total de jugadores: 3/10")
+	assertNotContains(t, roster, "<el 9>")
+	fake.expectSilence(t)
+}
+
+func TestVerPartidos(t *testing.T) {
+	chat := newTestGroup(t)
+	first := strconv.Itoa(chat.newGame(ana, "5"))
+	second := strconv.Itoa(chat.newGame(bruno, "7"))
+	chat.command(ana, "/yojuego "+first)
+	chat.command(ana, "/agregarinvitado "+first+" Pedro")
+
+	chat.command(ana, "/verpartidos",
+		"Proximos partidos:", "Partido "+first+", Jugadores: 2/10", "Partido "+second+", Jugadores: 0/14",
+		"/verpartido [numero de partido]")
+	chat.command(ana, "/partidos", "Proximos partidos:")
+}
+
+func TestAgregarDireccionConCancha(t *testing.T) {
+	chat := newTestGroup(t)
+	location := chat.sendMessage(&tgbotapi.Message{From: &ana, Location: &tgbotapi.Location{Latitude: -34.6, Longitude: -58.4}})
+	chat.reply(ana, location, "/agregarcancha Club Calle 123 | sintetico")
+	assertContains(t, chat.expect().text(), "Se guardo la cancha Club.")
+
+	id := strconv.Itoa(chat.newGame(ana, "5"))
+	chat.command(ana, "/agregardireccion "+id+" club", "Se ha agregado la cancha Club al partido "+id+".")
+
+	roster := chat.command(ana, "/verpartido "+id, "\n    - Direccion: Calle 123 (Club)\n")
+	assertNotContains(t, roster, "(Club) (Club)")
+	venue := chat.expect()
+	if venue.Method != "sendVenue" || venue.Params.Get("title") != "Club" || venue.Params.Get("latitude") != "-34.600000" {
+		t.Errorf("expected the venue of the game, got %s %v", venue.Method, venue.Params)
+	}
+}
+
+func TestCancelarPartido(t *testing.T) {
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "5"))
+
+	chat.command(bruno, "/cancelarpartido "+id, "Solo el organizador del partido puede usar /cancelarpartido.")
+	chat.command(ana, "/cancelarpartido "+id, "El partido ha sido cancelado por @Ana.")
+	chat.command(bruno, "/yojuego "+id, "No hay un partido pendiente con ese numero")
+}
+
+func TestDarseDeBaja(t *testing.T) {
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "5"))
+
+	chat.command(bruno, "/darsedebaja "+id, "No es posible darse de baja, @Bruno. No te encontras en el partido.")
+
+	chat.command(bruno, "/yojuego "+id)
+	chat.command(bruno, "/agregarinvitado "+id+" Pedro")
+	chat.command(bruno, "/darsedebaja "+id, "Te has dado de baja, @Bruno.", "Tambien se dieron de baja tus invitados: Pedro.")
+	if game := chat.lastGame(); len(game.Players) != 0 || len(game.Guests) != 0 {
+		t.Errorf("expected an empty game, got %+v", game)
+	}
+
+	chat.command(carla, "/yojuego "+id)
+	chat.command(carla, "/agregarinvitado "+id+" Juan")
+	chat.command(carla, "/leave "+id+" mantener", "Te has dado de baja, @Carla.", "Tus invitados (Juan) quedan a cargo del organizador.")
+	if game := chat.lastGame(); len(game.Guests) != 1 || game.Guests[0].InviterID != ana.ID {
+		t.Errorf("expected the guest to stay with the organizer, got %+v", game.Guests)
+	}
+}
+
+func TestInvitados(t *testing.T) {
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "1"))
+
+	chat.command(bruno, "/agregarinvitado "+id+" Pedro", "@Bruno has invitado a Pedro al partido.")
+	chat.command(carla, "/agregarinvitado "+id+" pedro", "Ya hay un invitado llamado pedro en el partido, @Carla.")
+	chat.command(bruno, "/agregartercero "+id+" Juan", "@Bruno has invitado a Juan al partido.")
+	chat.command(carla, "/agregarinvitado "+id+" Luis", "El partido esta completo @Carla, no podes invitar a Luis")
+
+	chat.command(carla, "/bajarinvitado "+id+" Pedro", "Solo quien invito a Pedro o el organizador del partido pueden darlo de baja.")
+	chat.command(bruno, "/bajarinvitado "+id+" Luis", "No es posible dar de baja a Luis. No se encuentra en el partido.")
+	chat.command(ana, "/bajarinvitado "+id+" Juan", "@Ana diste de baja a Juan.")
+	chat.command(bruno, "/bajarinvitado "+id+" Pedro", "@Bruno diste de baja a Pedro.")
+
+	chat.command(bruno, "/agregarinvitado "+id+" Pedro")
+	chat.command(bruno, "/agregarinvitado "+id+" Juan")
+	chat.command(bruno, "/agregarinvitado "+id+" Luis", "@Bruno ya invitaste 2 jugadores a este partido, no podes invitar mas.")
+}
+
+func TestRemoveLeavesStoredGameAlone(t *testing.T) {
+	game := createGame(newGame(-9401, 1, "5", 10))
+	game.Players = []int{1, 2, 3}
+	game.Guests = []Guest{{Name: "a", InviterID: 1}, {Name: "b", InviterID: 1}, {Name: "c", InviterID: 1}}
+	updateGame(game.Id, game)
+
+	copied, _ := getGame(game.Id)
+	copied.Guests = removeGuestAt(copied.Guests, 0)
+	copied.Players = remove(copied.Players, 1)
+
+	stored, _ := getGame(game.Id)
+	if !reflect.DeepEqual(stored.Players, []int{1, 2, 3}) || !reflect.DeepEqual(stored.Guests, []Guest{{Name: "a", InviterID: 1}, {Name: "b", InviterID: 1}, {Name: "c", InviterID: 1}}) {
+		t.Errorf("expected the stored game to keep its players and guests until it is updated, got %v and %+v", stored.Players, stored.Guests)
+	}
+}
+
+func TestCostoYPagos(t *testing.T) {
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "5"))
+
+	chat.command(bruno, "/pague "+id, "El partido "+id+" todavia no tiene un costo cargado, @Bruno.")
+	chat.command(ana, "/yojuego "+id)
+	chat.command(bruno, "/yojuego "+id)
+	chat.command(bruno, "/costo "+id+" 1000", "Solo el organizador del partido puede usar /costo.")
+	chat.command(ana, "/costo "+id+" mil", "mil no es un valor valido para monto.")
+	chat.command(ana, "/costo "+id+" 1000", "Se ha cargado un costo de $1000 al partido "+id+".", "Por ahora son $500 por jugador.")
+
+	chat.command(carla, "/pague "+id, "No tenes nada que pagar en este partido, @Carla.")
+	chat.command(ana, "/pague "+id, "Gracias @Ana, se registro tu pago de $500.")
+	chat.command(ana, "/pague "+id, "Ya habias marcado tu pago, @Ana.")
+
+	chat.command(carla, "/deudas", "Deudas pendientes:", "Bruno: $500", "Total adeudado: $500.")
+	chat.command(carla, "/verpartido "+id, "Costo: $1000 ($500 por jugador)", "Faltan pagar:", "Bruno: $500")
+
+	chat.command(bruno, "/pague "+id)
+	chat.command(carla, "/deudas", "No hay deudas pendientes.")
+
+	// A guest raises what Ana owes and lowers the share Bruno already paid, whose credit does not show as a debt.
+	chat.command(ana, "/agregarinvitado "+id+" Tito")
+	chat.command(ana, "/pague "+id, "Gracias @Ana, se registro tu pago de $168.")
+	chat.command(carla, "/deudas", "No hay deudas pendientes.")
+	chat.command(carla, "/yojuego "+id)
+	chat.command(carla, "/deudas", "Deudas pendientes:", "Carla Paz: $250", "Total adeudado: $250.")
+
+	// A cancelled game is not paid for.
+	chat.command(ana, "/cancelarpartido "+id)
+	chat.command(bruno, "/deudas", "No hay deudas pendientes.")
+}
+
+func TestSocios(t *testing.T) {
+	chat := newTestGroup(t)
+
+	chat.command(ana, "/socios", "Todavia no hay socios en este grupo.")
+	chat.command(ana, "/socios agregar", "Solo los administradores del grupo pueden sumar socios, @Ana.")
+	fake.setAdmin(chat.chat.ID, ana.ID, true)
+	chat.command(ana, "/socios agregar", "Ana ahora es socio del grupo.")
+	chat.command(ana, "/socios agregar", "Ana ya es socio.")
+
+	fromBruno := chat.send(bruno, "hola")
+	chat.reply(ana, fromBruno, "/socios agregar")
+	assertContains(t, chat.expect().text(), "Bruno ahora es socio del grupo.")
+	fromCarla := chat.send(carla, "hola")
+	chat.reply(carla, fromCarla, "/socios agregar")
+	assertContains(t, chat.expect().text(), "Solo los administradores del grupo pueden sumar socios, @Carla.")
+	chat.reply(bruno, fromCarla, "/socios agregar")
+	assertContains(t, chat.expect().text(), "Solo los administradores del grupo pueden sumar socios, @Bruno.")
+
+	chat.command(bruno, "/socios ventana 30", "Solo los administradores del grupo pueden cambiar la ventana de prioridad, @Bruno.")
+	chat.command(ana, "/socios ventana", "@Ana debes indicar los minutos de prioridad!")
+	chat.command(ana, "/socios ventana 30", "Los socios tendran 30 minutos de prioridad en los proximos partidos.")
+	chat.command(ana, "/socios", "Socios del grupo:", "1. Ana", "2. Bruno", "durante 30 minutos")
+	chat.command(ana, "/socios expulsar", "No conozco la opcion expulsar, @Ana.")
+
+	id := strconv.Itoa(chat.newGame(ana, "5"))
+	chat.command(carla, "/yojuego "+id, "@Carla los socios tienen prioridad hasta las")
+	chat.command(bruno, "/yojuego "+id, "¡Hola @Bruno!")
+	chat.command(ana, "/verpartido "+id, "Lista de espera", "1. Carla Paz")
+
+	fromAna := chat.send(ana, "hola")
+	chat.reply(bruno, fromAna, "/socios quitar")
+	assertContains(t, chat.expect().text(), "Solo los administradores del grupo pueden quitar a otros socios, @Bruno.")
+	chat.command(bruno, "/socios quitar", "Bruno ya no es socio del grupo.")
+	chat.command(ana, "/socios ventana 0", "Se desactivo la prioridad para socios.")
+}
+
+func TestPartidoFijo(t *testing.T) {
+	chat := newTestGroup(t)
+
+	chat.command(ana, "/partidofijo", "No hay partidos fijos.")
+	chat.command(ana, "/partidofijo martes", "debes proporcionar el dia, el horario y el tamaño")
+	chat.command(ana, "/partidofijo feriado 21:00 5", "feriado no es un dia de la semana valido.")
+	chat.command(ana, "/partidofijo martes tarde 5", "tarde no es un horario valido.")
+	chat.command(ana, "/partidofijo martes 21:00 50", "El tamaño especificado no es válido.")
+	chat.command(ana, "/partidofijo miércoles 21:00 5 Club", "Se creo el partido fijo:", "Todos los miercoles a las 21:00, partido de 5", "en Club")
+	chat.command(ana, "/partidofijo", "Partidos fijos:", "miercoles")
+
+	chat.command(ana, "/partidofijo anticipacion", "@Ana debes indicar el numero del partido fijo!")
+	chat.command(ana, "/partidofijo anticipacion 1", "debes indicar con cuantos dias de anticipacion")
+	chat.command(ana, "/partidofijo anticipacion 1 9", "9 no es una cantidad de dias valida.")
+	// Settings read before a change, like the scheduler's, must not see it happen under them.
+	before := getChatSettings(chat.chat.ID)
+	chat.command(ana, "/partidofijo anticipacion 1 2", "El partido fijo 1 se creara 2 dias antes de jugarse.")
+	chat.command(ana, "/partidofijo repetir 1", "Los jugadores de cada semana quedaran anotados en el partido siguiente.")
+	if recurring := before.RecurringGames[0]; recurring.DaysAhead == 2 || recurring.KeepRegulars {
+		t.Errorf("expected earlier settings to keep their recurring game, got %+v", recurring)
+	}
+	chat.command(ana, "/partidofijo repetir 1", "Ya no se anotaran automaticamente")
+	chat.command(bruno, "/partidofijo borrar 1", "Solo quien creo el partido fijo puede modificarlo.")
+	chat.command(ana, "/partidofijo borrar 7", "No hay un partido fijo con ese numero, @Ana.")
+	chat.command(ana, "/partidofijo borrar 1", "Se borro el partido fijo 1.")
+	chat.command(ana, "/partidofijo", "No hay partidos fijos.")
+}
+
+func TestRecurringGameWithInvalidSize(t *testing.T) {
+	chat := newTestGroup(t)
+	updateChatSettings(ChatSettings{ID: chat.chat.ID, RecurringGames: []RecurringGame{{Id: 1, OrganizerID: ana.ID, Weekday: time.Saturday, Hour: 21, Size: "50", DaysAhead: 3}}})
+
+	// A Friday, when no other test has a recurring game due.
+	friday := time.Date(2024, 5, 24, 10, 0, 0, 0, time.Local)
+	instantiateRecurringGames(friday)
+	fake.expectSilence(t)
+	if recurring := getChatSettings(chat.chat.ID).RecurringGames[0]; recurring.LastGameID != 0 || recurring.LastDate.IsZero() {
+		t.Errorf("expected the week to be skipped without a game, got %+v", recurring)
+	}
+}
+
+func TestCanchas(t *testing.T) {
+	chat := newTestGroup(t)
+
+	chat.command(ana, "/canchas", "No hay canchas guardadas.")
+	chat.command(ana, "/agregarcancha", "para usar /agregarcancha debes proporcionar nombre")
+	chat.command(ana, "/agregarcancha Club | techada", "@Ana debes agregar la direccion de la cancha!")
+	chat.command(ana, "/agregarcancha Club Calle 123", "Se guardo la cancha Club.")
+	chat.command(ana, "/agregarcancha club Calle 456 | techada", "Se actualizo la cancha club.")
+	chat.command(ana, "/canchas", "Canchas guardadas:", "Calle 456", "techada")
+	chat.command(ana, "/borrarcancha Polideportivo", "No hay una cancha guardada con el nombre Polideportivo.")
+	chat.command(ana, "/borrarcancha Club", "Se borro la cancha Club.")
+	chat.command(ana, "/canchas", "No hay canchas guardadas.")
+}
+
+func TestVotarFecha(t *testing.T) {
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "5"))
+
+	chat.command(ana, "/cerrarvotacion "+id, "El partido "+id+" no tiene una votacion abierta.")
+	chat.command(bruno, "/votarfecha "+id+" martes | jueves", "Solo el organizador del partido puede usar /votarfecha.")
+	chat.command(ana, "/votarfecha "+id+" martes", "debes proporcionar entre 2 y 10 opciones")
+
+	chat.send(ana, "/votarfecha "+id+" martes 21:00 | jueves 20:00")
+	poll := chat.expect()
+	assertContains(t, poll.text(), "Votacion de fecha para el partido "+id, "martes 21:00: 0 votos", "jueves 20:00: 0 votos")
+	assertContains(t, poll.Params.Get("reply_markup"), "fecha:"+id+":1")
+	chat.command(ana, "/votarfecha "+id+" lunes | viernes", "Ya hay una votacion abierta para el partido "+id+".")
+
+	chat.press(bruno, poll, "fecha:"+id+":1")
+	edit := chat.expect()
+	if edit.Method != "editMessageText" || edit.MessageID != poll.MessageID {
+		t.Errorf("expected the poll to be edited, got %s of message %d", edit.Method, edit.MessageID)
+	}
+	assertContains(t, edit.text(), "jueves 20:00: 1 voto")
+	assertContains(t, chat.expect().text(), "Votaste jueves 20:00")
+
+	chat.send(bruno, "/cerrarvotacion "+id)
+	assertContains(t, chat.expect().text(), "Solo el organizador del partido puede usar /cerrarvotacion.")
+	chat.send(ana, "/cerrarvotacion "+id)
+	assertContains(t, chat.expect().text(), "Votacion cerrada.")
+	assertContains(t, chat.expect().text(), "La fecha elegida es jueves 20:00 con 1 voto.")
+	if game := chat.lastGame(); game.DatePoll != nil || len(game.Date) != 1 || game.Date[0] != "jueves" || game.Schedule[0] != "20:00" {
+		t.Errorf("expected the winner in the game, got %+v", game)
+	}
+
+	chat.press(carla, poll, "fecha:"+id+":0")
+	assertContains(t, chat.expect().text(), "La votacion ya esta cerrada.")
+
+	chat.command(ana, "/votarfecha "+id+" 0h lunes | viernes", "0h no es un valor valido para plazo")
+	chat.command(ana, "/votarfecha "+id+" 200h lunes | viernes", "@Ana la votacion puede durar como mucho 7 dias.")
+	chat.send(ana, "/votarfecha "+id+" 48h lunes | viernes")
+	poll = chat.expect()
+	deadline := clock().Add(48 * time.Hour)
+	assertContains(t, poll.text(), "cierra el "+deadline.Format("02/01"), "lunes: 0 votos")
+
+	// Votes arriving together each see the votes of the others.
+	handlers.Wait()
+	for i := 0; i < 10; i++ {
+		voter := tgbotapi.User{ID: 200 + i, FirstName: "Votante"}
+		fake.inject(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			ID: "callback", From: &voter, Message: &tgbotapi.Message{MessageID: poll.MessageID, Chat: chat.chat}, Data: "fecha:" + id + ":1",
+		}})
+	}
+	for i := 0; i < 20; i++ {
+		chat.expect()
+	}
+	if votes := chat.lastGame().DatePoll.count(1); votes != 10 {
+		t.Errorf("expected 10 votes, got %d", votes)
+	}
+}
+
+func TestAyuda(t *testing.T) {
+	group := newTestGroup(t)
+	help := group.command(ana, "/ayuda", "Los comandos disponibles son:", "/yojuego [numero de partido] - Únete a un partido", "/socios")
+	assertNotContains(t, help, `\[`)
+	group.command(ana, "/start", "Los comandos disponibles son:")
+
+	private := newTestPrivateChat(t, ana)
+	help = private.command(ana, "/ayuda", "Los comandos disponibles son:", "/verpartidos")
+	assertNotContains(t, help, "/socios", "/deudas")
+}
+
+func TestPlantilla(t *testing.T) {
+	chat := newTestGroup(t)
+	fake.setAdmin(chat.chat.ID, ana.ID, true)
+
+	chat.command(bruno, "/plantilla", "Solo los administradores del grupo pueden usar /plantilla.")
+	chat.command(ana, "/plantilla", "Plantillas de mensajes.", "partido", "lista", "creacion", "recordatorio")
+	chat.command(ana, "/plantilla fixture", "No hay una plantilla llamada fixture.")
+	chat.command(ana, "/plantilla lista", "Plantilla lista:", "<pre>", "Asi se ve con un partido de ejemplo:")
+	chat.command(ana, "/plantilla lista {{range .Games", "La plantilla no es valida:")
+
+	chat.command(ana, "/plantilla lista Hay {{len .Games}} partidos, {{.User}}", "Se guardo la plantilla lista.")
+	chat.command(ana, "/plantilla", "lista (personalizada)")
+	chat.command(bruno, "/verpartidos", "partidos, Bruno")
+
+	chat.command(ana, "/plantilla lista restaurar", "Se restauro la plantilla lista.")
+	chat.command(bruno, "/verpartidos", "Proximos partidos:")
+}
+
+func TestIdioma(t *testing.T) {
+	chat := newTestGroup(t)
+
+	chat.command(bruno, "/idioma en", "Solo los administradores del grupo pueden usar /idioma.")
+	fake.setAdmin(chat.chat.ID, ana.ID, true)
+	chat.command(ana, "/idioma", "El idioma de este chat es Español.", "en (English)")
+	chat.command(ana, "/idioma klingon", "No conozco el idioma klingon.")
+	chat.command(ana, "/idioma en", "Done, I now speak English in this chat.")
+	chat.command(ana, "/help", "The available commands are:", "/join [game number]")
+	chat.command(ana, "/foo", "Unknown command. Use /help")
+
+	chat.command(ana, "/newgame 5", "A new 5 a side game was started. You can join it with /join")
+	id := strconv.Itoa(chat.lastGame().Id)
+	chat.command(bruno, "/join "+id, "Hi @Bruno! You joined the game.")
+	chat.command(bruno, "/yojuego "+id, "You are already in the game @Bruno.")
+
+	chat.command(ana, "/language português", "Pronto, agora falo Português neste chat.")
+	chat.command(ana, "/idioma es", "Listo, ahora hablo Español en este chat.")
+
+	private := newTestPrivateChat(t, bruno)
+	private.command(bruno, "/idioma", "El idioma de este chat es Español.")
+}
+
+func TestUnknownCommand(t *testing.T) {
+	chat := newTestGroup(t)
+	chat.command(ana, "/foo", "Comando desconocido. Usa /ayuda para ver la lista de comandos disponibles.")
+	chat.send(ana, "hola a todos")
+	fake.expectSilence(t)
+}
+
+func TestMenuDescription(t *testing.T) {
+	description := menuDescription(strings.Repeat("ñ", 300))
+	if !utf8.ValidString(description) || utf8.RuneCountInString(description) != 256 || !strings.HasSuffix(description, "ñ...") {
+		t.Errorf("expected 253 characters and an ellipsis, got %q", description)
+	}
+}
+
+func TestPublishCommands(t *testing.T) {
+	before := len(fake.calls("setMyCommands"))
+	publishCommands(bot)
+	published := fake.calls("setMyCommands")[before:]
+	if len(published) != len(commandMenuScopes)*len(commandMenuLanguages) {
+		t.Fatalf("expected a menu per scope and language, got %d", len(published))
+	}
+	for _, call := range published {
+		if call.Params.Get("language_code") == "en" {
+			assertContains(t, call.Params.Get("commands"), `"command":"join"`)
+		}
+	}
+}
@@ -2,25 +2,395 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 )
 
+// The configuration is read from a JSON file, then from FULBOT_* environment variables and then from command line
+// flags, each one overriding the previous. Everything except the token has a default. See reload.go for the settings
+// that can change while the bot runs.
+
 type Config struct {
-	Token string
+	Token   string        `json:"token"`
+	Storage StorageConfig `json:"storage"`
+	// Timezone is the IANA name of the zone games are played in, like America/Argentina/Buenos_Aires.
+	Timezone string `json:"timezone"`
+	// Language is the language of chats that did not choose one with /idioma.
+	Language string `json:"language"`
+	// Admins are the Telegram user IDs of the people running the bot, who can manage every game and chat.
+	Admins  []int         `json:"admins"`
+	Webhook WebhookConfig `json:"webhook"`
+	// MetricsListen is the address to serve /metrics, /healthz and /readyz on, like :9090, empty to not serve them.
+	MetricsListen string `json:"metrics_listen"`
+	// AdminSocket is the Unix socket the admin commands reach the running bot through, empty to not serve it.
+	AdminSocket string `json:"admin_socket"`
+	// LogLevel is one of debug, info, warn or error.
+	LogLevel string `json:"log_level"`
+	// LogFormat is text for people or json for log collectors.
+	LogFormat string `json:"log_format"`
+	// MaxGameSize is the most players per team a game can have.
+	MaxGameSize int `json:"max_game_size"`
+	// GameSizes are the sizes offered as buttons when creating a game, those over MaxGameSize are left out.
+	GameSizes  []string        `json:"game_sizes"`
+	RateLimits RateLimitConfig `json:"rate_limits"`
+	// Templates replace the default message templates in every chat that did not write its own, by template name.
+	Templates map[string]string `json:"templates"`
+	Backups   BackupConfig      `json:"backups"`
+	// Recording is a JSONL file to record updates and messages to for `fulbot replay`, empty to not record.
+	Recording string `json:"recording"`
+
+	location *time.Location
+	// file is where the configuration was read from, watched for changes.
+	file string
+}
+
+type StorageConfig struct {
+	Backend string `json:"backend"`
+	DSN     string `json:"dsn"`
+}
+
+// WebhookConfig makes Telegram push updates to the bot instead of the bot polling for them, when URL is set.
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// Listen is the address the bot serves the webhook on, behind whatever proxy URL points to.
+	Listen string `json:"listen"`
+}
+
+// BackupConfig takes compressed snapshots of the games and chats every so often, keeping the newest ones.
+type BackupConfig struct {
+	// Directory is where the snapshots go, empty to not take them.
+	Directory       string `json:"directory"`
+	IntervalMinutes int    `json:"interval_minutes"`
+	Keep            int    `json:"keep"`
+}
+
+// RateLimitConfig keeps the messages the bot sends within the limits of Telegram. Zero means no limit.
+type RateLimitConfig struct {
+	PrivateChatPerMinute int `json:"private_chat_per_minute"`
+	GroupChatPerMinute   int `json:"group_chat_per_minute"`
+	GlobalPerSecond      int `json:"global_per_second"`
+}
+
+var storageBackends = []string{"memory"}
+var logLevels = []string{"debug", "info", "warn", "error"}
+var logFormats = []string{"text", "json"}
+
+const defaultConfigFile = "config.json"
+const maxAllowedGameSize = 50
+
+func defaultConfig() *Config {
+	return &Config{
+		Storage:     StorageConfig{Backend: "memory"},
+		Timezone:    "Local",
+		Language:    "es",
+		LogLevel:    "info",
+		LogFormat:   "text",
+		MaxGameSize: 15,
+		GameSizes:   []string{"5", "6", "7", "8", "11"},
+		RateLimits:  RateLimitConfig{PrivateChatPerMinute: 60, GroupChatPerMinute: 20, GlobalPerSecond: 30},
+		Backups:     BackupConfig{IntervalMinutes: 360, Keep: 28},
+		location:    time.Local,
+	}
+}
+
+var activeConfig atomic.Pointer[Config]
+
+// getConfig returns the configuration in use. It can be replaced by a reload at any time, so code that needs several
+// settings to agree should get it once.
+func getConfig() *Config {
+	if config := activeConfig.Load(); config != nil {
+		return config
+	}
+	return defaultConfig()
 }
 
-func getConfig(filename string) (*Config, error) {
+func setConfig(config *Config) {
+	activeConfig.Store(config)
+}
+
+// configSetting is a setting that can also come from the environment and the command line.
+type configSetting struct {
+	name  string
+	usage string
+	apply func(config *Config, value string) error
+}
+
+func (setting configSetting) envName() string {
+	return "FULBOT_" + strings.ToUpper(strings.ReplaceAll(setting.name, "-", "_"))
+}
+
+var configSettings = []configSetting{
+	{"token", "Telegram bot token", func(config *Config, value string) error {
+		config.Token = value
+		return nil
+	}},
+	{"storage", "storage backend: " + strings.Join(storageBackends, ", "), func(config *Config, value string) error {
+		config.Storage.Backend = value
+		return nil
+	}},
+	{"storage-dsn", "where the storage backend keeps its data", func(config *Config, value string) error {
+		config.Storage.DSN = value
+		return nil
+	}},
+	{"timezone", "time zone of the games, like America/Argentina/Buenos_Aires", func(config *Config, value string) error {
+		config.Timezone = value
+		return nil
+	}},
+	{"language", "default language: " + strings.Join(languages, ", "), func(config *Config, value string) error {
+		config.Language = value
+		return nil
+	}},
+	{"admins", "comma separated Telegram user IDs of the bot administrators", func(config *Config, value string) error {
+		admins := make([]int, 0)
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field == "" {
+				continue
+			}
+			id, err := strconv.Atoi(field)
+			if err != nil {
+				return fmt.Errorf("%q is not a user ID", field)
+			}
+			admins = append(admins, id)
+		}
+		config.Admins = admins
+		return nil
+	}},
+	{"webhook-url", "public HTTPS URL for Telegram to send updates to, polling is used when empty", func(config *Config, value string) error {
+		config.Webhook.URL = value
+		return nil
+	}},
+	{"webhook-listen", "address to serve the webhook on, like :8443", func(config *Config, value string) error {
+		config.Webhook.Listen = value
+		return nil
+	}},
+	{"metrics-listen", "address to serve Prometheus metrics and health checks on, like :9090", func(config *Config, value string) error {
+		config.MetricsListen = value
+		return nil
+	}},
+	{"admin-socket", "Unix socket for the fulbot games and players admin commands", func(config *Config, value string) error {
+		config.AdminSocket = value
+		return nil
+	}},
+	{"log-level", "log level: " + strings.Join(logLevels, ", "), func(config *Config, value string) error {
+		config.LogLevel = value
+		return nil
+	}},
+	{"log-format", "log format: " + strings.Join(logFormats, ", "), func(config *Config, value string) error {
+		config.LogFormat = value
+		return nil
+	}},
+	{"max-game-size", "most players per team in a game", func(config *Config, value string) error {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		config.MaxGameSize = size
+		return nil
+	}},
+	{"backup-dir", "directory to save snapshots of the games and chats to, none are taken when empty", func(config *Config, value string) error {
+		config.Backups.Directory = value
+		return nil
+	}},
+	{"record", "JSONL file to record updates and messages to for fulbot replay", func(config *Config, value string) error {
+		config.Recording = value
+		return nil
+	}},
+}
+
+// loadConfig builds the configuration from the file, the environment and the command line arguments.
+func loadConfig(args []string, getenv func(string) string) (*Config, error) {
+	flags := flag.NewFlagSet("fulbot", flag.ContinueOnError)
+	filename := flags.String("config", defaultConfigFile, "configuration file (FULBOT_CONFIG)")
+	values := make(map[string]*string)
+	for _, setting := range configSettings {
+		values[setting.name] = flags.String(setting.name, "", setting.usage+" ("+setting.envName()+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// Only a missing default file is fine, one asked for explicitly has to exist. The flag wins over the environment,
+	// as with every other setting.
+	explicitFile := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			explicitFile = true
+		}
+	})
+	if env := getenv("FULBOT_CONFIG"); env != "" && !explicitFile {
+		*filename = env
+		explicitFile = true
+	}
+
+	config := defaultConfig()
+	config.file = *filename
+	if err := readConfigFile(*filename, config); err != nil && (explicitFile || !errors.Is(err, os.ErrNotExist)) {
+		return nil, fmt.Errorf("error reading %s: %w", *filename, err)
+	}
+
+	problems := make([]string, 0)
+	for _, setting := range configSettings {
+		if value := getenv(setting.envName()); value != "" {
+			if err := setting.apply(config, value); err != nil {
+				problems = append(problems, setting.envName()+": "+err.Error())
+			}
+		}
+	}
+	flags.Visit(func(f *flag.Flag) {
+		for _, setting := range configSettings {
+			if setting.name == f.Name {
+				if err := setting.apply(config, *values[f.Name]); err != nil {
+					problems = append(problems, "-"+f.Name+": "+err.Error())
+				}
+			}
+		}
+	})
+	if len(problems) > 0 {
+		return nil, configError(problems)
+	}
+
+	return config, config.validate()
+}
+
+func readConfigFile(filename string, config *Config) error {
 	file, err := os.Open(filename)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer file.Close()
 
-	var config Config
-	err = json.NewDecoder(file).Decode(&config)
-	if err != nil {
-		return nil, err
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(config)
+}
+
+func configError(problems []string) error {
+	return errors.New("invalid configuration:\n  - " + strings.Join(problems, "\n  - "))
+}
+
+// validate checks every setting, reporting all the problems at once.
+func (config *Config) validate() error {
+	problems := make([]string, 0)
+
+	if config.Token == "" {
+		problems = append(problems, "the token is missing, set it in "+defaultConfigFile+", FULBOT_TOKEN or -token")
+	}
+	if !containsString(storageBackends, config.Storage.Backend) {
+		problems = append(problems, fmt.Sprintf("unknown storage backend %q, the options are: %s", config.Storage.Backend, strings.Join(storageBackends, ", ")))
+	}
+	if location, err := time.LoadLocation(config.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("unknown timezone %q", config.Timezone))
+	} else {
+		config.location = location
+	}
+	if !isSupportedLanguage(config.Language) {
+		problems = append(problems, fmt.Sprintf("unknown language %q, the options are: %s", config.Language, strings.Join(languages, ", ")))
+	}
+	for _, admin := range config.Admins {
+		if admin <= 0 {
+			problems = append(problems, fmt.Sprintf("%d is not a valid admin user ID", admin))
+		}
+	}
+	if config.Webhook.URL != "" {
+		if webhook, err := url.Parse(config.Webhook.URL); err != nil || webhook.Scheme != "https" || webhook.Host == "" {
+			problems = append(problems, fmt.Sprintf("the webhook URL %q must be an https:// URL", config.Webhook.URL))
+		}
+		if config.Webhook.Listen == "" {
+			problems = append(problems, "the webhook needs an address to listen on, like :8443")
+		}
+	}
+	if !containsString(logLevels, config.LogLevel) {
+		problems = append(problems, fmt.Sprintf("unknown log level %q, the options are: %s", config.LogLevel, strings.Join(logLevels, ", ")))
+	}
+	if !containsString(logFormats, config.LogFormat) {
+		problems = append(problems, fmt.Sprintf("unknown log format %q, the options are: %s", config.LogFormat, strings.Join(logFormats, ", ")))
+	}
+	if config.MaxGameSize < 1 || config.MaxGameSize > maxAllowedGameSize {
+		problems = append(problems, fmt.Sprintf("the max game size must be between 1 and %d", maxAllowedGameSize))
+	}
+	for _, size := range config.GameSizes {
+		if number, err := strconv.Atoi(size); err != nil || number < 1 {
+			problems = append(problems, fmt.Sprintf("the game size %q must be a positive number", size))
+		}
+	}
+	if config.Backups.IntervalMinutes < 1 || config.Backups.Keep < 1 {
+		problems = append(problems, "backups need an interval of at least a minute and to keep at least one snapshot")
+	}
+	limits := config.RateLimits
+	if limits.PrivateChatPerMinute < 0 || limits.GroupChatPerMinute < 0 || limits.GlobalPerSecond < 0 {
+		problems = append(problems, "rate limits cannot be negative")
 	}
+	for name, source := range config.Templates {
+		if !containsString(templateNames, name) {
+			problems = append(problems, fmt.Sprintf("unknown template %q, the options are: %s", name, strings.Join(templateNames, ", ")))
+		} else if _, err := executeTemplate(config.Language, source, previewTemplateData(config.Language, &tgbotapi.User{FirstName: "Diego"})); err != nil {
+			problems = append(problems, fmt.Sprintf("the %s template is not valid: %v", name, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return configError(problems)
+	}
+	return nil
+}
+
+// redact hides a secret, keeping enough of it to tell which one is in use.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 8 {
+		return "[redacted]"
+	}
+	return secret[:4] + "…[redacted]"
+}
+
+// String shows the configuration with the token and the storage DSN redacted, so it can be logged.
+func (config Config) String() string {
+	config.Token = redact(config.Token)
+	config.Storage.DSN = redact(config.Storage.DSN)
+	encoded, _ := json.Marshal(config)
+	return string(encoded)
+}
 
-	return &config, nil
+// isBotAdmin tells if a user runs the bot.
+func isBotAdmin(userID int) bool {
+	for _, admin := range getConfig().Admins {
+		if admin == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConfig puts the settings that live outside the config in place.
+func applyConfig(config *Config) {
+	defaultLanguage = config.Language
+}
+
+// timezone is where the games are played. It is kept in the config rather than in time.Local, so it does not change
+// what local time means for the rest of the process.
+func timezone() *time.Location {
+	if location := getConfig().location; location != nil {
+		return location
+	}
+	return time.Local
+}
+
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(filename, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func environment(values map[string]string) func(string) string {
+	return func(name string) string {
+		return values[name]
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	filename := writeConfigFile(t, `{"token": "from-file", "language": "pt", "timezone": "America/Argentina/Buenos_Aires", "admins": [1], "max_game_size": 11}`)
+	env := environment(map[string]string{"FULBOT_LANGUAGE": "en", "FULBOT_ADMINS": "2, 3", "FULBOT_MAX_GAME_SIZE": "9"})
+
+	config, err := loadConfig([]string{"-config", filename, "-max-game-size", "7"}, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Token != "from-file" || config.Timezone != "America/Argentina/Buenos_Aires" {
+		t.Errorf("expected the file settings, got %+v", config)
+	}
+	if config.Language != "en" || len(config.Admins) != 2 || config.Admins[1] != 3 {
+		t.Errorf("expected the environment to override the file, got %+v", config)
+	}
+	if config.MaxGameSize != 7 {
+		t.Errorf("expected the flags to override the environment, got %d", config.MaxGameSize)
+	}
+	if config.Storage.Backend != "memory" || config.LogLevel != "info" {
+		t.Errorf("expected the defaults, got %+v", config)
+	}
+	if config.location.String() != "America/Argentina/Buenos_Aires" {
+		t.Errorf("expected the time zone to be loaded, got %v", config.location)
+	}
+}
+
+func TestConfigTimezone(t *testing.T) {
+	config, err := loadConfig([]string{"-token", "x", "-timezone", "Asia/Tokyo"}, environment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := time.Local
+	previous := getConfig()
+	applyConfig(config)
+	setConfig(config)
+	defer setConfig(previous)
+
+	if location := clock().Location(); location.String() != "Asia/Tokyo" {
+		t.Errorf("expected the clock in the configured time zone, got %v", location)
+	}
+	if time.Local != local {
+		t.Error("expected the process time zone to stay as it was")
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	if _, err := loadConfig([]string{"-config", missing, "-token", "x"}, environment(nil)); err == nil {
+		t.Error("expected an error for a config file that does not exist")
+	}
+
+	directory := t.TempDir()
+	previous, _ := os.Getwd()
+	os.Chdir(directory)
+	defer os.Chdir(previous)
+	if _, err := loadConfig(nil, environment(map[string]string{"FULBOT_TOKEN": "x"})); err != nil {
+		t.Errorf("expected the default file to be optional, got %v", err)
+	}
+
+	filename := writeConfigFile(t, `{"Token": "old-style"}`)
+	if config, err := loadConfig(nil, environment(map[string]string{"FULBOT_CONFIG": filename})); err != nil || config.Token != "old-style" {
+		t.Errorf("expected the old config file to still work, got %v", err)
+	}
+
+	filename = writeConfigFile(t, `{"token": "x", "tokn": "typo"}`)
+	if _, err := loadConfig([]string{"-config", filename}, environment(nil)); err == nil || !strings.Contains(err.Error(), "tokn") {
+		t.Errorf("expected unknown settings to be rejected, got %v", err)
+	}
+
+	flagged := writeConfigFile(t, `{"token": "from-flag"}`)
+	env := environment(map[string]string{"FULBOT_CONFIG": missing})
+	if config, err := loadConfig([]string{"-config", flagged}, env); err != nil || config.Token != "from-flag" {
+		t.Errorf("expected -config to override FULBOT_CONFIG, got %v", err)
+	}
+}
+
+func TestLoadConfigValidation(t *testing.T) {
+	args := []string{
+		"-storage", "mariadb",
+		"-timezone", "Mars/Olympus",
+		"-language", "fr",
+		"-webhook-url", "http://example.com/hook",
+		"-log-level", "loud",
+		"-max-game-size", "0",
+	}
+	_, err := loadConfig(args, environment(map[string]string{"FULBOT_CONFIG": writeConfigFile(t, `{}`)}))
+	if err == nil {
+		t.Fatal("expected an invalid configuration")
+	}
+	assertContains(t, err.Error(),
+		"the token is missing", `unknown storage backend "mariadb"`, `unknown timezone "Mars/Olympus"`, `unknown language "fr"`,
+		"must be an https:// URL", "needs an address to listen on", `unknown log level "loud"`, "max game size must be between 1 and 50")
+
+	_, err = loadConfig([]string{"-token", "x", "-max-game-size", "many"}, environment(map[string]string{"FULBOT_ADMINS": "1,ana"}))
+	if err == nil {
+		t.Fatal("expected values that do not parse to be rejected")
+	}
+	assertContains(t, err.Error(), `FULBOT_ADMINS: "ana" is not a user ID`, `-max-game-size: "many" is not a number`)
+}
+
+func TestConfigRedactsSecrets(t *testing.T) {
+	config := defaultConfig()
+	config.Token = "123456789:AAHsecretsecretsecret"
+	config.Storage.DSN = "user:password@tcp(db)/fulbot"
+
+	printed := config.String()
+	assertNotContains(t, printed, "AAHsecret", "password")
+	assertContains(t, printed, "[redacted]", `"max_game_size":15`)
+	if config.Token != "123456789:AAHsecretsecretsecret" {
+		t.Error("printing the config should not change it")
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	previous := getConfig()
+	defer setConfig(previous)
+
+	filename := writeConfigFile(t, `{"token": "x", "admins": [1], "game_sizes": ["5", "7"]}`)
+	args := []string{"-config", filename}
+	config, err := loadConfig(args, environment(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	setConfig(config)
+
+	changed := `{"token": "y", "language": "en", "admins": [2], "rate_limits": {"group_chat_per_minute": 5},
+		"templates": {"roster": "{{.Game.Count}} jugadores"}, "log_level": "debug"}`
+	if err := os.WriteFile(filename, []byte(changed), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloadConfig(args, environment(nil)); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := getConfig()
+	if !isBotAdmin(2) || isBotAdmin(1) {
+		t.Errorf("expected the admins to change, got %v", reloaded.Admins)
+	}
+	if reloaded.RateLimits.GroupChatPerMinute != 5 || reloaded.RateLimits.PrivateChatPerMinute != 60 || chatSendInterval(-1) != 12*time.Second {
+		t.Errorf("expected the rate limits to change, got %+v", reloaded.RateLimits)
+	}
+	if reloaded.LogLevel != "debug" || baseTemplate(templateRoster) != "{{.Game.Count}} jugadores" || len(offeredGameSizes()) != 5 {
+		t.Errorf("expected the log level, templates and game sizes to change, got %+v", reloaded)
+	}
+	if reloaded.Token != "x" || reloaded.Language != "es" {
+		t.Errorf("expected the token and language to need a restart, got %+v", reloaded)
+	}
+
+	if err := os.WriteFile(filename, []byte(`{"token": "x", "admins": [-3], "templates": {"roster": "{{.Nope"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloadConfig(args, environment(nil)); err == nil {
+		t.Error("expected an invalid configuration to be rejected")
+	}
+	if getConfig() != reloaded {
+		t.Error("expected the previous configuration to stay after a rejected reload")
+	}
+}
@@ -10,3 +10,4 @@ var emojiThumbsUp = "\U0001F44D"
 var emojiThumbsDown = "\U0001F44E"
 var emojiGhost = "\U0001F47B"
 var unicodeBulletPoint = "\u2022"
+var emojiMoney = "\U0001F4B0"
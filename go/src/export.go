@@ -0,0 +1,762 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Exports carry the games and chats of the bot in a format of their own, so they can move between storage backends
+// and be read in a spreadsheet. JSON has everything the bot keeps. CSV has a row per game, player, guest, waitlist
+// entry, payment and venue, for spreadsheets; chat settings, recurring games, date polls and users are only in JSON.
+// exportVersion goes up whenever a field changes meaning or goes away, and imports refuse versions they do not know.
+// Version 2 records the amount of each payment instead of who paid.
+
+const exportVersion = 2
+
+type Export struct {
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Games      []ExportedGame `json:"games"`
+	Chats      []ExportedChat `json:"chats"`
+	Users      []ExportedUser `json:"users"`
+	// venuesOnly marks the exports read from CSV, whose chats carry their venues and nothing else.
+	venuesOnly bool
+}
+
+type ExportedGame struct {
+	ID          int                     `json:"id"`
+	ChatID      int64                   `json:"chat_id"`
+	Active      bool                    `json:"active"`
+	Size        string                  `json:"size"`
+	MaxPlayers  int                     `json:"max_players"`
+	OrganizerID int                     `json:"organizer_id"`
+	Date        string                  `json:"date,omitempty"`
+	Schedule    string                  `json:"schedule,omitempty"`
+	Address     string                  `json:"address,omitempty"`
+	Venue       string                  `json:"venue,omitempty"`
+	Cost        int                     `json:"cost,omitempty"`
+	Players     []int                   `json:"players"`
+	Guests      []ExportedGuest         `json:"guests"`
+	Waitlist    []ExportedWaitlistEntry `json:"waitlist"`
+	Payments    []ExportedPayment       `json:"payments"`
+	// Paid are the players that paid their whole share, only in version 1.
+	Paid          []int             `json:"paid,omitempty"`
+	PriorityUntil *time.Time        `json:"priority_until,omitempty"`
+	DatePoll      *ExportedDatePoll `json:"date_poll,omitempty"`
+}
+
+type ExportedPayment struct {
+	UserID int `json:"user_id"`
+	Amount int `json:"amount"`
+}
+
+type ExportedGuest struct {
+	Name      string `json:"name"`
+	InvitedBy int    `json:"invited_by"`
+}
+
+// ExportedWaitlistEntry is a player waiting for a place, or a guest of theirs when GuestName is set.
+type ExportedWaitlistEntry struct {
+	PlayerID  int    `json:"player_id"`
+	GuestName string `json:"guest_name,omitempty"`
+}
+
+type ExportedDatePoll struct {
+	Options []string `json:"options"`
+	// Votes maps each user to the index of the option they voted for.
+	Votes     map[int]int `json:"votes"`
+	Deadline  time.Time   `json:"deadline"`
+	MessageID int         `json:"message_id"`
+}
+
+type ExportedChat struct {
+	ID                    int64                   `json:"id"`
+	Language              string                  `json:"language,omitempty"`
+	Members               []int                   `json:"members"`
+	PriorityWindowSeconds int                     `json:"priority_window_seconds,omitempty"`
+	Venues                []ExportedVenue         `json:"venues"`
+	RecurringGames        []ExportedRecurringGame `json:"recurring_games"`
+	Templates             map[string]string       `json:"templates,omitempty"`
+}
+
+type ExportedVenue struct {
+	Name     string            `json:"name"`
+	Address  string            `json:"address"`
+	Location *ExportedLocation `json:"location,omitempty"`
+	Notes    string            `json:"notes,omitempty"`
+}
+
+type ExportedLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type ExportedRecurringGame struct {
+	ID          int `json:"id"`
+	OrganizerID int `json:"organizer_id"`
+	// Weekday counts from 0 for Sunday.
+	Weekday      int        `json:"weekday"`
+	Hour         int        `json:"hour"`
+	Minute       int        `json:"minute"`
+	Size         string     `json:"size"`
+	Address      string     `json:"address,omitempty"`
+	DaysAhead    int        `json:"days_ahead"`
+	KeepRegulars bool       `json:"keep_regulars,omitempty"`
+	LastGameID   int        `json:"last_game_id,omitempty"`
+	LastDate     *time.Time `json:"last_date,omitempty"`
+}
+
+type ExportedUser struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	UserName  string `json:"username,omitempty"`
+}
+
+// exportData takes a snapshot of the games, chats and users of a chat, or of every chat when chatID is zero.
+func exportData(chatID int64) Export {
+	data := Export{Version: exportVersion, ExportedAt: clock(), Games: []ExportedGame{}, Chats: []ExportedChat{}, Users: []ExportedUser{}}
+	people := make(map[int]bool)
+
+	mutex.Lock()
+	for _, game := range games {
+		if chatID == 0 || game.ChatID == chatID {
+			data.Games = append(data.Games, exportGame(game))
+			people[game.OrganizerID] = true
+			for _, id := range game.Players {
+				people[id] = true
+			}
+			for _, guest := range game.Guests {
+				people[guest.InviterID] = true
+			}
+			for _, entry := range game.Waitlist {
+				people[entry.PlayerID] = true
+			}
+			for _, payment := range game.Payments {
+				people[payment.PlayerID] = true
+			}
+		}
+	}
+	mutex.Unlock()
+	sort.Slice(data.Games, func(i, j int) bool { return data.Games[i].ID < data.Games[j].ID })
+
+	chatsMutex.Lock()
+	for _, chat := range chats {
+		if chatID == 0 || chat.ID == chatID {
+			data.Chats = append(data.Chats, exportChat(chat))
+			for _, id := range chat.Members {
+				people[id] = true
+			}
+		}
+	}
+	chatsMutex.Unlock()
+	sort.Slice(data.Chats, func(i, j int) bool { return data.Chats[i].ID < data.Chats[j].ID })
+
+	usersMutex.Lock()
+	for id, known := range knownUsers {
+		if chatID == 0 || people[id] {
+			user := known.User
+			data.Users = append(data.Users, ExportedUser{ID: user.ID, FirstName: user.FirstName, LastName: user.LastName, UserName: user.UserName})
+		}
+	}
+	usersMutex.Unlock()
+	sort.Slice(data.Users, func(i, j int) bool { return data.Users[i].ID < data.Users[j].ID })
+	return data
+}
+
+func exportGame(game Game) ExportedGame {
+	exported := ExportedGame{
+		ID:          game.Id,
+		ChatID:      game.ChatID,
+		Active:      game.Active,
+		Size:        game.Size,
+		MaxPlayers:  game.MaxPlayers,
+		OrganizerID: game.OrganizerID,
+		Date:        strings.Join(game.Date, " "),
+		Schedule:    strings.Join(game.Schedule, " "),
+		Address:     strings.Join(game.Address, " "),
+		Venue:       game.Venue,
+		Cost:        game.Cost,
+		Players:     append([]int{}, game.Players...),
+		Guests:      make([]ExportedGuest, 0, len(game.Guests)),
+		Waitlist:    make([]ExportedWaitlistEntry, 0, len(game.Waitlist)),
+		Payments:    make([]ExportedPayment, 0, len(game.Payments)),
+	}
+	for _, payment := range game.Payments {
+		exported.Payments = append(exported.Payments, ExportedPayment{UserID: payment.PlayerID, Amount: payment.Amount})
+	}
+	for _, guest := range game.Guests {
+		exported.Guests = append(exported.Guests, ExportedGuest{Name: guest.Name, InvitedBy: guest.InviterID})
+	}
+	for _, entry := range game.Waitlist {
+		exported.Waitlist = append(exported.Waitlist, ExportedWaitlistEntry{PlayerID: entry.PlayerID, GuestName: entry.GuestName})
+	}
+	if !game.PriorityUntil.IsZero() {
+		until := game.PriorityUntil
+		exported.PriorityUntil = &until
+	}
+	if poll := game.DatePoll; poll != nil {
+		votes := make(map[int]int, len(poll.Votes))
+		for user, option := range poll.Votes {
+			votes[user] = option
+		}
+		exported.DatePoll = &ExportedDatePoll{Options: append([]string{}, poll.Options...), Votes: votes, Deadline: poll.Deadline, MessageID: poll.MessageID}
+	}
+	return exported
+}
+
+func exportChat(chat ChatSettings) ExportedChat {
+	exported := ExportedChat{
+		ID:                    chat.ID,
+		Language:              chat.Language,
+		Members:               append([]int{}, chat.Members...),
+		PriorityWindowSeconds: int(chat.PriorityWindow / time.Second),
+		Venues:                make([]ExportedVenue, 0, len(chat.Venues)),
+		RecurringGames:        make([]ExportedRecurringGame, 0, len(chat.RecurringGames)),
+		Templates:             chat.Templates,
+	}
+	for _, venue := range chat.Venues {
+		exported.Venues = append(exported.Venues, exportVenue(venue))
+	}
+	for _, recurring := range chat.RecurringGames {
+		exportedRecurring := ExportedRecurringGame{
+			ID:           recurring.Id,
+			OrganizerID:  recurring.OrganizerID,
+			Weekday:      int(recurring.Weekday),
+			Hour:         recurring.Hour,
+			Minute:       recurring.Minute,
+			Size:         recurring.Size,
+			Address:      strings.Join(recurring.Address, " "),
+			DaysAhead:    recurring.DaysAhead,
+			KeepRegulars: recurring.KeepRegulars,
+			LastGameID:   recurring.LastGameID,
+		}
+		if !recurring.LastDate.IsZero() {
+			last := recurring.LastDate
+			exportedRecurring.LastDate = &last
+		}
+		exported.RecurringGames = append(exported.RecurringGames, exportedRecurring)
+	}
+	return exported
+}
+
+func exportVenue(venue Venue) ExportedVenue {
+	exported := ExportedVenue{Name: venue.Name, Address: venue.Address, Notes: venue.Notes}
+	if venue.HasLocation {
+		exported.Location = &ExportedLocation{Latitude: venue.Latitude, Longitude: venue.Longitude}
+	}
+	return exported
+}
+
+// importData stores the games, chats and users of an export, replacing those with the same ID, and returns how many
+// games and chats it stored. Nothing is stored unless the whole export is valid. The chats of a CSV export only add
+// or replace venues, keeping the rest of the settings of the chat.
+func importData(data Export) (int, int, error) {
+	imported, importedChats, err := prepareImport(data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	mutex.Lock()
+	for _, game := range imported {
+		games[game.Id] = game
+		if game.Id >= nextGameId {
+			nextGameId = game.Id + 1
+		}
+	}
+	mutex.Unlock()
+
+	chatsMutex.Lock()
+	for _, chat := range importedChats {
+		if data.venuesOnly {
+			chat = mergeVenues(chats[chat.ID], chat)
+		}
+		chats[chat.ID] = chat
+	}
+	chatsMutex.Unlock()
+
+	rememberExportedUsers(data.Users)
+	return len(imported), len(importedChats), nil
+}
+
+// mergeVenues adds the venues of imported to the settings of the same chat, replacing those with the same name.
+func mergeVenues(existing ChatSettings, imported ChatSettings) ChatSettings {
+	existing.ID = imported.ID
+	for _, venue := range imported.Venues {
+		if index, found := existing.findVenue(venue.Name); found {
+			existing.Venues[index] = venue
+		} else {
+			existing.Venues = append(existing.Venues, venue)
+		}
+	}
+	return existing
+}
+
+// restoreData replaces every game and chat with those of an export, once it is known to be valid.
+func restoreData(data Export) error {
+	if data.venuesOnly {
+		return errors.New("a CSV export lacks the chat settings, restore from a JSON one")
+	}
+	restored, restoredChats, err := prepareImport(data)
+	if err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	games = make(map[int]Game, len(restored))
+	nextGameId = 1
+	for _, game := range restored {
+		games[game.Id] = game
+		if game.Id >= nextGameId {
+			nextGameId = game.Id + 1
+		}
+	}
+	mutex.Unlock()
+
+	chatsMutex.Lock()
+	chats = make(map[int64]ChatSettings, len(restoredChats))
+	for _, chat := range restoredChats {
+		chats[chat.ID] = chat
+	}
+	chatsMutex.Unlock()
+
+	rememberExportedUsers(data.Users)
+	return nil
+}
+
+// prepareImport checks an export and turns it into games and chats, without storing them.
+func prepareImport(data Export) ([]Game, []ChatSettings, error) {
+	if data.Version < 1 || data.Version > exportVersion {
+		return nil, nil, fmt.Errorf("unsupported export version %d, this bot reads versions 1 to %d", data.Version, exportVersion)
+	}
+	imported := make([]Game, 0, len(data.Games))
+	seenGames := make(map[int]bool)
+	for _, exported := range data.Games {
+		game, err := importGame(exported)
+		if err != nil {
+			return nil, nil, err
+		}
+		if seenGames[game.Id] {
+			return nil, nil, fmt.Errorf("game %d is there twice", game.Id)
+		}
+		seenGames[game.Id] = true
+		imported = append(imported, game)
+	}
+	importedChats := make([]ChatSettings, 0, len(data.Chats))
+	seenChats := make(map[int64]bool)
+	for _, exported := range data.Chats {
+		if exported.ID == 0 {
+			return nil, nil, errors.New("a chat has no ID")
+		}
+		if seenChats[exported.ID] {
+			return nil, nil, fmt.Errorf("chat %d is there twice", exported.ID)
+		}
+		seenChats[exported.ID] = true
+		importedChats = append(importedChats, importChat(exported))
+	}
+	return imported, importedChats, nil
+}
+
+func rememberExportedUsers(users []ExportedUser) {
+	for _, exported := range users {
+		rememberUser(&tgbotapi.User{ID: exported.ID, FirstName: exported.FirstName, LastName: exported.LastName, UserName: exported.UserName})
+	}
+}
+
+func importGame(exported ExportedGame) (Game, error) {
+	if exported.ID < 1 || exported.ChatID == 0 || exported.MaxPlayers < 1 {
+		return Game{}, fmt.Errorf("game %d needs an ID, a chat and a number of players", exported.ID)
+	}
+	game := Game{
+		Id:          exported.ID,
+		ChatID:      exported.ChatID,
+		Active:      exported.Active,
+		Size:        exported.Size,
+		MaxPlayers:  exported.MaxPlayers,
+		OrganizerID: exported.OrganizerID,
+		Date:        strings.Fields(exported.Date),
+		Schedule:    strings.Fields(exported.Schedule),
+		Address:     strings.Fields(exported.Address),
+		Venue:       exported.Venue,
+		Cost:        exported.Cost,
+		Players:     append(make([]int, 0, len(exported.Players)), exported.Players...),
+		Guests:      make([]Guest, 0, len(exported.Guests)),
+		Waitlist:    make([]WaitlistEntry, 0, len(exported.Waitlist)),
+		Payments:    make([]Payment, 0, len(exported.Payments)),
+	}
+	for _, payment := range exported.Payments {
+		game.Payments = append(game.Payments, Payment{PlayerID: payment.UserID, Amount: payment.Amount})
+	}
+	for _, guest := range exported.Guests {
+		game.Guests = append(game.Guests, Guest{Name: guest.Name, InviterID: guest.InvitedBy})
+	}
+	for _, playerID := range exported.Paid {
+		game.Payments = append(game.Payments, Payment{PlayerID: playerID, Amount: game.amountOwedBy(playerID)})
+	}
+	for _, entry := range exported.Waitlist {
+		game.Waitlist = append(game.Waitlist, WaitlistEntry{PlayerID: entry.PlayerID, GuestName: entry.GuestName})
+	}
+	if exported.PriorityUntil != nil {
+		game.PriorityUntil = *exported.PriorityUntil
+	}
+	if poll := exported.DatePoll; poll != nil {
+		// A poll without votes may come as null, the bot counts on a map to record them.
+		votes := make(map[int]int, len(poll.Votes))
+		for user, option := range poll.Votes {
+			votes[user] = option
+		}
+		game.DatePoll = &DatePoll{Options: append([]string{}, poll.Options...), Votes: votes, Deadline: poll.Deadline, MessageID: poll.MessageID}
+	}
+	return game, nil
+}
+
+func importChat(exported ExportedChat) ChatSettings {
+	chat := ChatSettings{
+		ID:             exported.ID,
+		Language:       exported.Language,
+		Members:        append(make([]int, 0, len(exported.Members)), exported.Members...),
+		PriorityWindow: time.Duration(exported.PriorityWindowSeconds) * time.Second,
+		Templates:      exported.Templates,
+	}
+	for _, venue := range exported.Venues {
+		chat.Venues = append(chat.Venues, importVenue(venue))
+	}
+	for _, recurring := range exported.RecurringGames {
+		imported := RecurringGame{
+			Id:           recurring.ID,
+			OrganizerID:  recurring.OrganizerID,
+			Weekday:      time.Weekday(recurring.Weekday),
+			Hour:         recurring.Hour,
+			Minute:       recurring.Minute,
+			Size:         recurring.Size,
+			Address:      strings.Fields(recurring.Address),
+			DaysAhead:    recurring.DaysAhead,
+			KeepRegulars: recurring.KeepRegulars,
+			LastGameID:   recurring.LastGameID,
+		}
+		if recurring.LastDate != nil {
+			imported.LastDate = *recurring.LastDate
+		}
+		chat.RecurringGames = append(chat.RecurringGames, imported)
+	}
+	return chat
+}
+
+func importVenue(exported ExportedVenue) Venue {
+	venue := Venue{Name: exported.Name, Address: exported.Address, Notes: exported.Notes}
+	if exported.Location != nil {
+		venue.HasLocation = true
+		venue.Latitude = exported.Location.Latitude
+		venue.Longitude = exported.Location.Longitude
+	}
+	return venue
+}
+
+// Export formats.
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+var exportFormats = []string{formatJSON, formatCSV}
+
+// encodeExport writes an export in the given format.
+func encodeExport(w io.Writer, data Export, format string) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	case formatCSV:
+		return writeExportCSV(w, data)
+	}
+	return fmt.Errorf("unknown export format %q, the options are: %s", format, strings.Join(exportFormats, ", "))
+}
+
+// decodeExport reads an export in either format, telling them apart by the first character.
+func decodeExport(contents []byte) (Export, error) {
+	var data Export
+	if trimmed := bytes.TrimSpace(contents); len(trimmed) > 0 && trimmed[0] == '{' {
+		var version struct {
+			Version int `json:"version"`
+		}
+		if err := json.Unmarshal(trimmed, &version); err != nil {
+			return data, err
+		}
+		if version.Version > exportVersion {
+			return data, fmt.Errorf("unsupported export version %d, this bot reads versions 1 to %d", version.Version, exportVersion)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&data)
+		return data, err
+	}
+	return readExportCSV(bytes.NewReader(contents))
+}
+
+// csvVersionLine starts every CSV export, before the header.
+const csvVersionLine = "# fulbot export version "
+
+var csvHeader = []string{"record", "game_id", "chat_id", "active", "size", "max_players", "organizer_id", "date", "schedule",
+	"address", "venue", "cost", "priority_until", "user_id", "name", "invited_by", "amount", "latitude", "longitude", "notes"}
+
+// csvHeaderFor is the header of a version, version 1 had no amount and its payments were whole shares.
+func csvHeaderFor(version int) []string {
+	if version > 1 {
+		return csvHeader
+	}
+	header := make([]string, 0, len(csvHeader))
+	for _, column := range csvHeader {
+		if column != "amount" {
+			header = append(header, column)
+		}
+	}
+	return header
+}
+
+// csvRow is a CSV line by column name.
+type csvRow map[string]string
+
+func (row csvRow) values() []string {
+	values := make([]string, len(csvHeader))
+	for i, column := range csvHeader {
+		values[i] = row[column]
+	}
+	return values
+}
+
+func writeExportCSV(w io.Writer, data Export) error {
+	if _, err := fmt.Fprintf(w, "%s%d\n", csvVersionLine, data.Version); err != nil {
+		return err
+	}
+	names := make(map[int]string)
+	for _, user := range data.Users {
+		names[user.ID] = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write(csvHeader)
+	for _, game := range data.Games {
+		ids := csvRow{"game_id": strconv.Itoa(game.ID), "chat_id": strconv.FormatInt(game.ChatID, 10)}
+		row := csvRow{
+			"record":       "game",
+			"active":       strconv.FormatBool(game.Active),
+			"size":         game.Size,
+			"max_players":  strconv.Itoa(game.MaxPlayers),
+			"organizer_id": strconv.Itoa(game.OrganizerID),
+			"date":         game.Date,
+			"schedule":     game.Schedule,
+			"address":      game.Address,
+			"venue":        game.Venue,
+			"cost":         strconv.Itoa(game.Cost),
+		}
+		if game.PriorityUntil != nil {
+			row["priority_until"] = game.PriorityUntil.Format(time.RFC3339)
+		}
+		writeCSVRow(writer, ids, row)
+		for _, player := range game.Players {
+			writeCSVRow(writer, ids, csvRow{"record": "player", "user_id": strconv.Itoa(player), "name": names[player]})
+		}
+		for _, guest := range game.Guests {
+			writeCSVRow(writer, ids, csvRow{"record": "guest", "name": guest.Name, "invited_by": strconv.Itoa(guest.InvitedBy)})
+		}
+		for _, entry := range game.Waitlist {
+			writeCSVRow(writer, ids, csvRow{"record": "waitlist", "user_id": strconv.Itoa(entry.PlayerID), "name": entry.GuestName})
+		}
+		for _, payment := range game.Payments {
+			writeCSVRow(writer, ids, csvRow{"record": "payment", "user_id": strconv.Itoa(payment.UserID), "name": names[payment.UserID], "amount": strconv.Itoa(payment.Amount)})
+		}
+	}
+	for _, chat := range data.Chats {
+		for _, venue := range chat.Venues {
+			row := csvRow{"record": "venue", "name": venue.Name, "address": venue.Address, "notes": venue.Notes}
+			if venue.Location != nil {
+				row["latitude"] = strconv.FormatFloat(venue.Location.Latitude, 'f', -1, 64)
+				row["longitude"] = strconv.FormatFloat(venue.Location.Longitude, 'f', -1, 64)
+			}
+			writeCSVRow(writer, csvRow{"chat_id": strconv.FormatInt(chat.ID, 10)}, row)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeCSVRow(writer *csv.Writer, ids csvRow, row csvRow) {
+	for column, value := range ids {
+		row[column] = value
+	}
+	writer.Write(row.values())
+}
+
+// readExportCSV reads a CSV export back. Player names are left out, as the rows only show them.
+func readExportCSV(r io.Reader) (Export, error) {
+	data := Export{Games: []ExportedGame{}, Chats: []ExportedChat{}, Users: []ExportedUser{}, venuesOnly: true}
+	reader := bufio.NewReader(r)
+	first, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(first, csvVersionLine) {
+		return data, errors.New("not a fulbot export, it must start with " + strings.TrimSpace(csvVersionLine) + " and a number")
+	}
+	if data.Version, err = strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(first, csvVersionLine))); err != nil {
+		return data, fmt.Errorf("invalid export version: %w", err)
+	}
+	if data.Version < 1 || data.Version > exportVersion {
+		return data, fmt.Errorf("unsupported export version %d, this bot reads versions 1 to %d", data.Version, exportVersion)
+	}
+
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return data, err
+	}
+	header := csvHeaderFor(data.Version)
+	if len(records) == 0 || strings.Join(records[0], ",") != strings.Join(header, ",") {
+		return data, errors.New("the CSV header does not match the export version")
+	}
+
+	gameIndex := make(map[int]int)
+	chatIndex := make(map[int64]int)
+	for line, values := range records[1:] {
+		row := make(csvRow)
+		for i, column := range header {
+			row[column] = values[i]
+		}
+		if err := readCSVRow(&data, row, gameIndex, chatIndex); err != nil {
+			return data, fmt.Errorf("line %d: %w", line+3, err)
+		}
+	}
+	return data, nil
+}
+
+func readCSVRow(data *Export, row csvRow, gameIndex map[int]int, chatIndex map[int64]int) error {
+	var problems []string
+	number := func(column string) int {
+		if row[column] == "" {
+			return 0
+		}
+		value, err := strconv.Atoi(row[column])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s %q is not a number", column, row[column]))
+		}
+		return value
+	}
+	chatID, err := strconv.ParseInt(row["chat_id"], 10, 64)
+	if err != nil {
+		return fmt.Errorf("chat_id %q is not a number", row["chat_id"])
+	}
+
+	if row["record"] == "venue" {
+		index, exists := chatIndex[chatID]
+		if !exists {
+			index = len(data.Chats)
+			chatIndex[chatID] = index
+			data.Chats = append(data.Chats, ExportedChat{ID: chatID, Members: []int{}, Venues: []ExportedVenue{}, RecurringGames: []ExportedRecurringGame{}})
+		}
+		venue := ExportedVenue{Name: row["name"], Address: row["address"], Notes: row["notes"]}
+		if row["latitude"] != "" || row["longitude"] != "" {
+			latitude, latitudeErr := strconv.ParseFloat(row["latitude"], 64)
+			longitude, longitudeErr := strconv.ParseFloat(row["longitude"], 64)
+			if latitudeErr != nil || longitudeErr != nil {
+				return errors.New("invalid venue location")
+			}
+			venue.Location = &ExportedLocation{Latitude: latitude, Longitude: longitude}
+		}
+		data.Chats[index].Venues = append(data.Chats[index].Venues, venue)
+		return nil
+	}
+
+	id := number("game_id")
+	if row["record"] == "game" {
+		game := ExportedGame{
+			ID:          id,
+			ChatID:      chatID,
+			Active:      row["active"] == "true",
+			Size:        row["size"],
+			MaxPlayers:  number("max_players"),
+			OrganizerID: number("organizer_id"),
+			Date:        row["date"],
+			Schedule:    row["schedule"],
+			Address:     row["address"],
+			Venue:       row["venue"],
+			Cost:        number("cost"),
+			Players:     []int{},
+			Guests:      []ExportedGuest{},
+			Waitlist:    []ExportedWaitlistEntry{},
+			Payments:    []ExportedPayment{},
+		}
+		if row["priority_until"] != "" {
+			until, err := time.Parse(time.RFC3339, row["priority_until"])
+			if err != nil {
+				return fmt.Errorf("invalid priority_until: %w", err)
+			}
+			game.PriorityUntil = &until
+		}
+		gameIndex[id] = len(data.Games)
+		data.Games = append(data.Games, game)
+	} else {
+		index, exists := gameIndex[id]
+		if !exists {
+			return fmt.Errorf("game %d has to come before its %s rows", id, row["record"])
+		}
+		game := &data.Games[index]
+		switch row["record"] {
+		case "player":
+			game.Players = append(game.Players, number("user_id"))
+		case "guest":
+			game.Guests = append(game.Guests, ExportedGuest{Name: row["name"], InvitedBy: number("invited_by")})
+		case "waitlist":
+			game.Waitlist = append(game.Waitlist, ExportedWaitlistEntry{PlayerID: number("user_id"), GuestName: row["name"]})
+		case "payment":
+			if data.Version == 1 {
+				game.Paid = append(game.Paid, number("user_id"))
+			} else {
+				game.Payments = append(game.Payments, ExportedPayment{UserID: number("user_id"), Amount: number("amount")})
+			}
+		default:
+			return fmt.Errorf("unknown record %q", row["record"])
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// handleExportarCommand sends the administrator who asks a file with the games and venues of the group, in a private
+// chat so the rest of the group does not get the payments and user IDs.
+func handleExportarCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	format := strings.ToLower(args.Text("formato"))
+	if format == "" {
+		format = formatJSON
+	}
+	if !containsString(exportFormats, format) {
+		respondToMessage(message, tr(args.Language, "export.unknown_format", "format", format, "options", strings.Join(exportFormats, ", ")))
+		return
+	}
+
+	var contents bytes.Buffer
+	if err := encodeExport(&contents, exportData(message.Chat.ID), format); err != nil {
+		slog.Error("error exporting a chat", "chat_id", message.Chat.ID, "error", err)
+		return
+	}
+	name := fmt.Sprintf("fulbot-%d-%s.%s", message.Chat.ID, clock().Format("2006-01-02"), format)
+	document := tgbotapi.NewDocumentUpload(int64(message.From.ID), tgbotapi.FileBytes{Name: name, Bytes: contents.Bytes()})
+	document.Caption = tr(args.Language, "export.caption", "version", exportVersion)
+	if _, err := deliver(int64(message.From.ID), document); err != nil {
+		// Telegram refuses to write to users who never opened a private chat with the bot.
+		slog.Warn("error sending an export", "chat_id", message.Chat.ID, "user_id", message.From.ID, "error", err)
+		respondToMessage(message, tr(args.Language, "export.private_failed", "name", message.From.FirstName))
+		return
+	}
+	respondToMessage(message, tr(args.Language, "export.sent", "name", message.From.FirstName))
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// exportFixture uses every field of the export format, with IDs no other test uses.
+func exportFixture() Export {
+	until := time.Date(2024, 5, 21, 20, 30, 0, 0, time.UTC)
+	last := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	return Export{
+		Version: exportVersion,
+		Games: []ExportedGame{
+			{
+				ID: 9001, ChatID: -9001, Active: true, Size: "5", MaxPlayers: 10, OrganizerID: 1,
+				Date: "martes 21/05", Schedule: "21:00", Address: "Av. Siempre Viva 742", Venue: "La Canchita", Cost: 10000,
+				Players:       []int{1, 2},
+				Guests:        []ExportedGuest{{Name: "Juan, \"el 9\"", InvitedBy: 1}},
+				Waitlist:      []ExportedWaitlistEntry{{PlayerID: 3}, {PlayerID: 3, GuestName: "Nico"}},
+				Payments:      []ExportedPayment{{UserID: 1, Amount: 6668}},
+				PriorityUntil: &until,
+				DatePoll:      &ExportedDatePoll{Options: []string{"martes", "jueves"}, Votes: map[int]int{1: 0, 2: 1}, Deadline: until, MessageID: 12},
+			},
+			{ID: 9002, ChatID: -9001, Size: "7", MaxPlayers: 14, OrganizerID: 2, Players: []int{}, Guests: []ExportedGuest{}, Waitlist: []ExportedWaitlistEntry{}, Payments: []ExportedPayment{}},
+		},
+		Chats: []ExportedChat{{
+			ID: -9001, Language: "en", Members: []int{1, 3}, PriorityWindowSeconds: 3600,
+			Venues: []ExportedVenue{
+				{Name: "La Canchita", Address: "Av. Siempre Viva 742", Location: &ExportedLocation{Latitude: -34.6, Longitude: -58.38}, Notes: "sintetico"},
+				{Name: "Club", Address: "Calle 1"},
+			},
+			RecurringGames: []ExportedRecurringGame{{ID: 1, OrganizerID: 1, Weekday: 2, Hour: 21, Size: "5", Address: "La Canchita", DaysAhead: 3, KeepRegulars: true, LastGameID: 9001, LastDate: &last}},
+			Templates:      map[string]string{templateRoster: "{{.Game.Count}}"},
+		}},
+		Users: []ExportedUser{{ID: 1, FirstName: "Diego", UserName: "diego10"}, {ID: 2, FirstName: "Lionel", LastName: "Messi"}, {ID: 3, FirstName: "Ana"}},
+	}
+}
+
+func TestExportJSONRoundTrip(t *testing.T) {
+	want := exportFixture()
+	var encoded bytes.Buffer
+	if err := encodeExport(&encoded, want, formatJSON); err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeExport(encoded.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("the JSON export changed on the way back:\nwant %+v\ngot  %+v", want, got)
+	}
+
+	games, chats, err := importData(got)
+	if err != nil || games != 2 || chats != 1 {
+		t.Fatalf("expected 2 games and 1 chat to be imported, got %d, %d, %v", games, chats, err)
+	}
+	exported := exportData(-9001)
+	exported.ExportedAt = want.ExportedAt
+	if !reflect.DeepEqual(exported, want) {
+		t.Errorf("the bot does not export what it imported:\nwant %+v\ngot  %+v", want, exported)
+	}
+	if game := createGame(newGame(-9001, 1, "5", 10)); game.Id <= 9002 {
+		t.Errorf("expected new games to be numbered after the imported ones, got %d", game.Id)
+	}
+}
+
+func TestExportCSVRoundTrip(t *testing.T) {
+	fixture := exportFixture()
+	var encoded bytes.Buffer
+	if err := encodeExport(&encoded, fixture, formatCSV); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, encoded.String(),
+		"# fulbot export version 2\nrecord,game_id,chat_id,",
+		"\ngame,9001,-9001,true,5,10,1,martes 21/05,21:00,Av. Siempre Viva 742,La Canchita,10000,2024-05-21T20:30:00Z,",
+		"\nplayer,9001,-9001,,,,,,,,,,,2,Lionel Messi,,,,,\n",
+		"\nguest,9001,-9001,,,,,,,,,,,,\"Juan, \"\"el 9\"\"\",1,,,,\n",
+		"\npayment,9001,-9001,,,,,,,,,,,1,Diego,,6668,,,\n",
+		"\nvenue,,-9001,,,,,,,Av. Siempre Viva 742,,,,,La Canchita,,,-34.6,-58.38,sintetico\n",
+	)
+
+	got, err := decodeExport(encoded.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// CSV only carries the games, their people and the venues.
+	want := fixture
+	want.Users = []ExportedUser{}
+	want.Games[0].DatePoll = nil
+	want.Chats = []ExportedChat{{ID: -9001, Members: []int{}, Venues: fixture.Chats[0].Venues, RecurringGames: []ExportedRecurringGame{}}}
+	want.venuesOnly = true
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("the CSV export changed on the way back:\nwant %+v\ngot  %+v", want, got)
+	}
+}
+
+func TestImportCSVKeepsChatSettings(t *testing.T) {
+	chatID := int64(-9101)
+	updateChatSettings(ChatSettings{
+		ID:             chatID,
+		Language:       "en",
+		Members:        []int{1, 3},
+		PriorityWindow: time.Hour,
+		Venues:         []Venue{{Name: "Club", Address: "Calle 1"}, {Name: "Potrero", Address: "Calle 9"}},
+		RecurringGames: []RecurringGame{{Id: 1, OrganizerID: 1, Weekday: time.Tuesday, Hour: 21, Size: "5", DaysAhead: 3}},
+		Templates:      map[string]string{templateRoster: "{{.Game.Count}}"},
+	})
+	contents := "# fulbot export version 2\n" + strings.Join(csvHeader, ",") + "\n" +
+		"venue,,-9101,,,,,,,Calle 2,,,,,club,,,,,techada\n" +
+		"venue,,-9101,,,,,,,Av. Siempre Viva 742,,,,,La Canchita,,,,,\n"
+	data, err := decodeExport([]byte(contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, chats, err := importData(data); err != nil || chats != 1 {
+		t.Fatalf("expected 1 chat to be imported, got %d, %v", chats, err)
+	}
+
+	chat := getChatSettings(chatID)
+	if chat.Language != "en" || !reflect.DeepEqual(chat.Members, []int{1, 3}) || chat.PriorityWindow != time.Hour ||
+		len(chat.RecurringGames) != 1 || len(chat.Templates) != 1 {
+		t.Errorf("expected the CSV import to keep the settings of the chat, got %+v", chat)
+	}
+	venues := []Venue{{Name: "club", Address: "Calle 2", Notes: "techada"}, {Name: "Potrero", Address: "Calle 9"}, {Name: "La Canchita", Address: "Av. Siempre Viva 742"}}
+	if !reflect.DeepEqual(chat.Venues, venues) {
+		t.Errorf("expected the venues to be merged by name, got %+v", chat.Venues)
+	}
+	if err := restoreData(data); err == nil {
+		t.Error("expected a CSV export to be refused as a snapshot")
+	}
+}
+
+func TestImportDatePollWithoutVotes(t *testing.T) {
+	contents := `{"version": 2, "exported_at": "2024-05-21T20:30:00Z", "games": [{"id": 9201, "chat_id": -9201, "active": true,
+		"size": "5", "max_players": 10, "organizer_id": 1, "players": [], "guests": [], "waitlist": [], "payments": [],
+		"date_poll": {"options": ["martes", "jueves"], "votes": null, "deadline": "2024-05-21T20:30:00Z", "message_id": 12}}],
+		"chats": [], "users": []}`
+	data, err := decodeExport([]byte(contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	games, _, err := prepareImport(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poll := games[0].DatePoll; poll == nil || poll.Votes == nil {
+		t.Fatalf("expected the poll to come with an empty map of votes, got %+v", poll)
+	}
+	games[0].DatePoll.Votes[2] = 1
+}
+
+func TestImportRejectsUnknownVersions(t *testing.T) {
+	for _, contents := range []string{
+		`{"version": 3, "games": []}`,
+		`{"version": 1, "games": [], "colour": "red"}`,
+		"# fulbot export version 3\n",
+		"# fulbot export version 2\nrecord,game_id\n",
+		"game_id,chat_id\n1,2\n",
+	} {
+		if _, err := decodeExport([]byte(contents)); err == nil {
+			t.Errorf("expected %q to be refused", contents)
+		}
+	}
+	if _, _, err := importData(Export{Version: 1, Games: []ExportedGame{{ID: 1}}}); err == nil {
+		t.Error("expected a game without chat to be refused")
+	}
+}
+
+func TestImportVersion1Payments(t *testing.T) {
+	game := `{"id": 1, "chat_id": -1, "active": true, "size": "5", "max_players": 10, "organizer_id": 1, "cost": 1000,
+		"players": [1, 2], "guests": [{"name": "Tito", "invited_by": 1}], "waitlist": [], "paid": [1]}`
+	csv := "# fulbot export version 1\n" + strings.Join(csvHeaderFor(1), ",") + "\n" +
+		"game,1,-1,true,5,10,1,,,,,1000,,,,,,,\n" +
+		"player,1,-1,,,,,,,,,,,1,Diego,,,,\n" +
+		"player,1,-1,,,,,,,,,,,2,Lionel,,,,\n" +
+		"guest,1,-1,,,,,,,,,,,,Tito,1,,,\n" +
+		"payment,1,-1,,,,,,,,,,,1,Diego,,,,\n"
+	for _, contents := range []string{`{"version": 1, "games": [` + game + `], "chats": [], "users": []}`, csv} {
+		data, err := decodeExport([]byte(contents))
+		if err != nil {
+			t.Fatal(err)
+		}
+		imported, _, err := prepareImport(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A version 1 payment covered the whole share of the player and their guests.
+		if got := imported[0].Payments; !reflect.DeepEqual(got, []Payment{{PlayerID: 1, Amount: 668}}) {
+			t.Errorf("expected the version 1 payment to cover $668, got %+v", got)
+		}
+	}
+}
+
+func TestExportar(t *testing.T) {
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "5"))
+	chat.command(bruno, "/yojuego "+id, "¡Hola @Bruno!")
+
+	chat.command(bruno, "/exportar", "Solo los administradores del grupo pueden usar /exportar.")
+	fake.setAdmin(chat.chat.ID, ana.ID, true)
+	chat.command(ana, "/exportar xml", "No conozco el formato xml, las opciones son: json, csv")
+
+	chat.send(ana, "/exportar csv")
+	document := newTestPrivateChat(t, ana).expect()
+	if document.Method != "sendDocument" {
+		t.Fatalf("expected a document, got %s", document.Method)
+	}
+	assertContains(t, chat.expect().text(), "@Ana te mande el archivo por privado.")
+	if name := document.Params.Get("document_name"); !strings.HasPrefix(name, "fulbot-"+strconv.FormatInt(chat.chat.ID, 10)+"-") || !strings.HasSuffix(name, ".csv") {
+		t.Errorf("unexpected file name %q", name)
+	}
+	assertContains(t, document.Params.Get("caption"), "formato de exportacion 2")
+	assertContains(t, document.Params.Get("document"), "\ngame,"+id+",", "\nplayer,"+id+",", ",102,Bruno,")
+
+	fake.setBlocked(int64(ana.ID), true)
+	defer fake.setBlocked(int64(ana.ID), false)
+	chat.command(ana, "/exportar", "@Ana no pude mandarte el archivo por privado")
+
+	output, err := runAdminCommand([]string{"export", "-chat", strconv.FormatInt(chat.chat.ID, 10)}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, output, `"version": 2`, `"id": `+id)
+	if output, err := runAdminCommand([]string{"import"}, output); err != nil || output != "Imported 1 games and 0 chats." {
+		t.Errorf("expected the export to import back, got %q, %v", output, err)
+	}
+}
+
+func TestExportUsersOfGuestsAndWaitlist(t *testing.T) {
+	rememberUser(&tgbotapi.User{ID: 9301, FirstName: "Invita"})
+	rememberUser(&tgbotapi.User{ID: 9302, FirstName: "Espera"})
+	game := newGame(-9301, 9303, "5", 10)
+	game.Guests = []Guest{{Name: "Nico", InviterID: 9301}}
+	game.Waitlist = []WaitlistEntry{{PlayerID: 9302}}
+	createGame(game)
+
+	users := exportData(-9301).Users
+	if len(users) != 2 || users[0].ID != 9301 || users[1].ID != 9302 {
+		t.Errorf("expected the inviter and the waiting player among the users, got %+v", users)
+	}
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// fakeToken is the token the tests log in with, the fake server accepts any.
+const fakeToken = "123456:fake"
+
+// fakeBotUser is who the bot is on the fake server.
+var fakeBotUser = tgbotapi.User{ID: 1, FirstName: "FulBot", UserName: "fulbot_test", IsBot: true}
+
+// fakeRequest is a Bot API call the fake server received.
+type fakeRequest struct {
+	Method string
+	Params url.Values
+	// MessageID is the message the call sent or edited.
+	MessageID int
+}
+
+func (request fakeRequest) chatID() int64 {
+	id, _ := strconv.ParseInt(request.Params.Get("chat_id"), 10, 64)
+	return id
+}
+
+func (request fakeRequest) text() string {
+	return request.Params.Get("text")
+}
+
+// fakeTelegram is a local Bot API server. It hands out the updates injected by the tests, answers what the bot asks
+// with plausible results and records every call, sending the visible ones (messages, edits, venues and callback
+// answers) to the outgoing channel in the order they arrive.
+type fakeTelegram struct {
+	server   *httptest.Server
+	updates  chan tgbotapi.Update
+	outgoing chan fakeRequest
+
+	mutex         sync.Mutex
+	requests      []fakeRequest
+	admins        map[int64]map[int]bool
+	blocked       map[int64]bool
+	rejectHTML    map[int64]bool
+	failures      map[int64][]tgbotapi.APIResponse
+	nextUpdateID  int
+	nextMessageID int
+}
+
+func newFakeTelegram() *fakeTelegram {
+	fake := &fakeTelegram{
+		updates:       make(chan tgbotapi.Update, 100),
+		outgoing:      make(chan fakeRequest, 100),
+		admins:        make(map[int64]map[int]bool),
+		blocked:       make(map[int64]bool),
+		rejectHTML:    make(map[int64]bool),
+		failures:      make(map[int64][]tgbotapi.APIResponse),
+		nextUpdateID:  1,
+		nextMessageID: 1,
+	}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	return fake
+}
+
+func (fake *fakeTelegram) close() {
+	fake.server.Close()
+}
+
+// client returns a real Bot API client whose requests to api.telegram.org go to the fake server.
+func (fake *fakeTelegram) client() (TelegramClient, error) {
+	target, _ := url.Parse(fake.server.URL)
+	httpClient := &http.Client{Transport: redirectTransport{target: target}}
+	return tgbotapi.NewBotAPIWithClient(fakeToken, httpClient)
+}
+
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (transport redirectTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.URL.Scheme = transport.target.Scheme
+	request.URL.Host = transport.target.Host
+	request.Host = transport.target.Host
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+// setAdmin makes a user an administrator of a group, or takes it away.
+func (fake *fakeTelegram) setAdmin(chatID int64, userID int, admin bool) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if fake.admins[chatID] == nil {
+		fake.admins[chatID] = make(map[int]bool)
+	}
+	fake.admins[chatID][userID] = admin
+}
+
+// setBlocked makes the bot unable to write to a chat, like a user who never opened a private chat with it.
+func (fake *fakeTelegram) setBlocked(chatID int64, blocked bool) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.blocked[chatID] = blocked
+}
+
+// setRejectHTML makes the messages of a chat fail to parse whenever they come in HTML, as when a tag is broken.
+func (fake *fakeTelegram) setRejectHTML(chatID int64, reject bool) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.rejectHTML[chatID] = reject
+}
+
+// failNext makes the next call for a chat fail with the given answer, once for each time it is called.
+func (fake *fakeTelegram) failNext(chatID int64, failure tgbotapi.APIResponse) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	fake.failures[chatID] = append(fake.failures[chatID], failure)
+}
+
+// inject queues an update for the bot, numbering it and the message it carries.
+func (fake *fakeTelegram) inject(update tgbotapi.Update) tgbotapi.Update {
+	fake.mutex.Lock()
+	update.UpdateID = fake.nextUpdateID
+	fake.nextUpdateID++
+	if update.Message != nil && update.Message.MessageID == 0 {
+		update.Message.MessageID = fake.nextMessageID
+		fake.nextMessageID++
+	}
+	fake.mutex.Unlock()
+	fake.updates <- update
+	return update
+}
+
+// calls returns the recorded calls to a method.
+func (fake *fakeTelegram) calls(method string) []fakeRequest {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	calls := make([]fakeRequest, 0)
+	for _, request := range fake.requests {
+		if request.Method == method {
+			calls = append(calls, request)
+		}
+	}
+	return calls
+}
+
+// next waits for the next visible call of the bot.
+func (fake *fakeTelegram) next(t *testing.T) fakeRequest {
+	t.Helper()
+	select {
+	case request := <-fake.outgoing:
+		return request
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the bot to send something")
+		return fakeRequest{}
+	}
+}
+
+// expectSilence fails if the bot sends something within a short while.
+func (fake *fakeTelegram) expectSilence(t *testing.T) {
+	t.Helper()
+	select {
+	case request := <-fake.outgoing:
+		t.Fatalf("unexpected %s: %q", request.Method, request.text())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func (fake *fakeTelegram) serveHTTP(writer http.ResponseWriter, request *http.Request) {
+	parts := strings.Split(request.URL.Path, "/")
+	method := parts[len(parts)-1]
+	params, err := requestParams(request)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	call := fakeRequest{Method: method, Params: params}
+
+	if problem := checkCallbackData(params.Get("reply_markup")); problem != "" {
+		fake.record(call, false)
+		json.NewEncoder(writer).Encode(tgbotapi.APIResponse{Ok: false, ErrorCode: http.StatusBadRequest, Description: problem})
+		return
+	}
+	fake.mutex.Lock()
+	blocked := fake.blocked[call.chatID()]
+	rejectHTML := fake.rejectHTML[call.chatID()] && params.Get("parse_mode") == tgbotapi.ModeHTML
+	var failure *tgbotapi.APIResponse
+	if queued := fake.failures[call.chatID()]; call.chatID() != 0 && len(queued) > 0 {
+		failure = &queued[0]
+		fake.failures[call.chatID()] = queued[1:]
+	}
+	fake.mutex.Unlock()
+	if failure != nil {
+		fake.record(call, false)
+		json.NewEncoder(writer).Encode(failure)
+		return
+	}
+	if blocked {
+		fake.record(call, false)
+		json.NewEncoder(writer).Encode(tgbotapi.APIResponse{Ok: false, ErrorCode: http.StatusForbidden, Description: "Forbidden: bot can't initiate conversation with a user"})
+		return
+	}
+	if rejectHTML {
+		fake.record(call, false)
+		json.NewEncoder(writer).Encode(tgbotapi.APIResponse{Ok: false, ErrorCode: http.StatusBadRequest, Description: "Bad Request: can't parse entities: Unsupported start tag \"x\" at byte offset 0"})
+		return
+	}
+
+	var result interface{}
+	switch method {
+	case "getUpdates":
+		result = fake.pendingUpdates()
+	case "getMe":
+		result = fakeBotUser
+	case "sendMessage", "editMessageText", "sendVenue", "sendDocument":
+		result = fake.record(call, true)
+	case "answerCallbackQuery":
+		fake.record(call, true)
+		result = true
+	case "getChatMember":
+		fake.record(call, false)
+		result = fake.chatMember(call)
+	case "getMyCommands":
+		fake.record(call, false)
+		result = []BotCommand{}
+	default:
+		fake.record(call, false)
+		result = true
+	}
+
+	raw, _ := json.Marshal(result)
+	json.NewEncoder(writer).Encode(tgbotapi.APIResponse{Ok: true, Result: raw})
+}
+
+// checkCallbackData refuses buttons whose data goes over the 64 bytes Telegram allows.
+func checkCallbackData(markup string) string {
+	var keyboard tgbotapi.InlineKeyboardMarkup
+	if markup == "" || json.Unmarshal([]byte(markup), &keyboard) != nil {
+		return ""
+	}
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData != nil && len(*button.CallbackData) > 64 {
+				return "Bad Request: BUTTON_DATA_INVALID"
+			}
+		}
+	}
+	return ""
+}
+
+// requestParams reads the parameters of a call. Uploads come as multipart forms, their files are kept as parameters
+// with the file contents and, under <field>_name, the file name.
+func requestParams(request *http.Request) (url.Values, error) {
+	if !strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		err := request.ParseForm()
+		return request.PostForm, err
+	}
+	if err := request.ParseMultipartForm(1 << 20); err != nil {
+		return nil, err
+	}
+	params := url.Values(request.MultipartForm.Value)
+	for field, files := range request.MultipartForm.File {
+		file, err := files[0].Open()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		params.Set(field, string(contents))
+		params.Set(field+"_name", files[0].Filename)
+	}
+	return params, nil
+}
+
+// record keeps a call and returns the message Telegram would answer with.
+func (fake *fakeTelegram) record(call fakeRequest, visible bool) tgbotapi.Message {
+	fake.mutex.Lock()
+	fake.requests = append(fake.requests, call)
+	messageID, _ := strconv.Atoi(call.Params.Get("message_id"))
+	if messageID == 0 {
+		messageID = fake.nextMessageID
+		fake.nextMessageID++
+	}
+	call.MessageID = messageID
+	fake.mutex.Unlock()
+
+	if visible {
+		fake.outgoing <- call
+	}
+	return tgbotapi.Message{
+		MessageID: messageID,
+		From:      &fakeBotUser,
+		Chat:      &tgbotapi.Chat{ID: call.chatID()},
+		Date:      int(time.Now().Unix()),
+		Text:      call.text(),
+	}
+}
+
+func (fake *fakeTelegram) chatMember(call fakeRequest) tgbotapi.ChatMember {
+	userID, _ := strconv.Atoi(call.Params.Get("user_id"))
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	status := "member"
+	if fake.admins[call.chatID()][userID] {
+		status = "administrator"
+	}
+	return tgbotapi.ChatMember{User: &tgbotapi.User{ID: userID, FirstName: "Usuario " + strconv.Itoa(userID)}, Status: status}
+}
+
+// pendingUpdates waits a little for injected updates, like a short long poll.
+func (fake *fakeTelegram) pendingUpdates() []tgbotapi.Update {
+	updates := make([]tgbotapi.Update, 0)
+	select {
+	case update := <-fake.updates:
+		updates = append(updates, update)
+	case <-time.After(50 * time.Millisecond):
+		return updates
+	}
+	for {
+		select {
+		case update := <-fake.updates:
+			updates = append(updates, update)
+		default:
+			return updates
+		}
+	}
+}
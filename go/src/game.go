@@ -1,14 +1,101 @@
 package main
 
+import (
+	"strings"
+	"time"
+)
+
 type Game struct {
 	Id          int
 	Active      bool
 	Players     []int
-	Guests      []string
+	Guests      []Guest
 	OrganizerID int
 	Size        string
 	MaxPlayers  int
 	Address     []string
 	Schedule    []string
 	Date        []string
+	// Venue is the name of the saved venue the address was taken from, if any.
+	Venue  string
+	ChatID int64
+	Cost   int
+	// Payments are what the players paid, so later changes to their share still show up in what they owe.
+	Payments []Payment
+	// PriorityUntil is when the members-only sign-up window ends, zero if the game has none.
+	PriorityUntil time.Time
+	Waitlist      []WaitlistEntry
+	DatePoll      *DatePoll
+}
+
+// clone returns a copy of the game that shares no slice or map with it, so it can be changed outside the mutex.
+func (game Game) clone() Game {
+	game.Players = append([]int{}, game.Players...)
+	game.Guests = append([]Guest{}, game.Guests...)
+	game.Address = append([]string{}, game.Address...)
+	game.Schedule = append([]string{}, game.Schedule...)
+	game.Date = append([]string{}, game.Date...)
+	game.Payments = append([]Payment{}, game.Payments...)
+	game.Waitlist = append([]WaitlistEntry{}, game.Waitlist...)
+	if game.DatePoll != nil {
+		poll := *game.DatePoll
+		poll.Options = append([]string{}, poll.Options...)
+		poll.Votes = make(map[int]int, len(game.DatePoll.Votes))
+		for user, option := range game.DatePoll.Votes {
+			poll.Votes[user] = option
+		}
+		game.DatePoll = &poll
+	}
+	return game
+}
+
+type Guest struct {
+	Name      string
+	InviterID int
+}
+
+// maxGuestsPerPlayer caps how many guests a single player can bring to a game.
+var maxGuestsPerPlayer = 2
+
+func (game Game) guestsInvitedBy(playerID int) []Guest {
+	result := make([]Guest, 0)
+	for _, guest := range game.Guests {
+		if guest.InviterID == playerID {
+			result = append(result, guest)
+		}
+	}
+	return result
+}
+
+func (game Game) hasGuestNamed(name string) bool {
+	for _, guest := range game.Guests {
+		if strings.EqualFold(guest.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// findGuest looks up a guest by name, preferring the ones invited by the given player since names can repeat.
+func (game Game) findGuest(name string, playerID int) (int, bool) {
+	found := -1
+	for i, guest := range game.Guests {
+		if !strings.EqualFold(guest.Name, name) {
+			continue
+		}
+		if guest.InviterID == playerID {
+			return i, true
+		}
+		if found == -1 {
+			found = i
+		}
+	}
+	return found, found != -1
+}
+
+// removeGuestAt returns a new slice without the guest at index, leaving alone the array other copies of the game share.
+func removeGuestAt(guests []Guest, index int) []Guest {
+	remaining := make([]Guest, 0, len(guests)-1)
+	remaining = append(remaining, guests[:index]...)
+	return append(remaining, guests[index+1:]...)
 }
@@ -1,8 +1,7 @@
 module FulBot
 
-go 1.20
+go 1.21
 
-require (
-	github.com/go-telegram-bot-api/telegram-bot-api v4.6.4+incompatible // indirect
-	github.com/technoweenie/multipartstreamer v1.0.1 // indirect
-)
+require github.com/go-telegram-bot-api/telegram-bot-api v4.6.4+incompatible
+
+require github.com/technoweenie/multipartstreamer v1.0.1 // indirect
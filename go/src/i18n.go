@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+var defaultLanguage = "es"
+
+// catalogs holds the messages of every supported language by key. Messages that depend on a count
+// have one key per plural form, ending in ".one" and ".other".
+var catalogs = map[string]map[string]string{
+	"es": messagesES,
+	"en": messagesEN,
+	"pt": messagesPT,
+}
+
+// languages lists the supported language codes in the order /idioma shows them.
+var languages = []string{"es", "en", "pt"}
+
+// languageNames are shown by /idioma, in the language itself.
+var languageNames = map[string]string{
+	"es": "Español",
+	"en": "English",
+	"pt": "Português",
+}
+
+// tr translates a message, replacing each {placeholder} with the value that follows its name in params.
+// The result is HTML: values are escaped unless they are of type HTML.
+func tr(language string, key string, params ...interface{}) string {
+	message, exists := catalogs[language][key]
+	if !exists {
+		message, exists = catalogs[defaultLanguage][key]
+	}
+	if !exists {
+		return key
+	}
+	for i := 0; i+1 < len(params); i += 2 {
+		value, isHTML := params[i+1].(HTML)
+		if !isHTML {
+			value = HTML(escapeHTML(fmt.Sprint(params[i+1])))
+		}
+		message = strings.ReplaceAll(message, "{"+fmt.Sprint(params[i])+"}", string(value))
+	}
+	return message
+}
+
+// trn translates a message that depends on a count, which is also available as the {count} placeholder.
+func trn(language string, key string, count int, params ...interface{}) string {
+	return tr(language, key+"."+pluralForm(language, count), append([]interface{}{"count", count}, params...)...)
+}
+
+func pluralForm(language string, count int) string {
+	// Portuguese uses the singular for zero as well.
+	if count == 1 || (language == "pt" && count == 0) {
+		return "one"
+	}
+	return "other"
+}
+
+func isSupportedLanguage(language string) bool {
+	_, exists := catalogs[language]
+	return exists
+}
+
+func chatLanguage(chatID int64) string {
+	language := getChatSettings(chatID).Language
+	if !isSupportedLanguage(language) {
+		return defaultLanguage
+	}
+	return language
+}
+
+// isOption tells if a word is the given option of a command in the chat language, also accepting the Spanish one.
+func isOption(language string, word string, key string) bool {
+	return strings.EqualFold(word, tr(language, key)) || strings.EqualFold(word, tr(defaultLanguage, key))
+}
+
+// errorMessages maps the errors shown to users to their message keys.
+var errorMessages = map[error]string{
+	errInvalidSize: "error.invalid_size",
+}
+
+func translateError(language string, err error) string {
+	if key, exists := errorMessages[err]; exists {
+		return tr(language, key, "max", getConfig().MaxGameSize)
+	}
+	return err.Error()
+}
+
+func handleIdiomaCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	var response string
+
+	requested := strings.ToLower(args.Text("idioma"))
+	for code, name := range languageNames {
+		if strings.EqualFold(requested, name) {
+			requested = code
+		}
+	}
+
+	if requested == "" {
+		options := make([]string, 0, len(languages))
+		for _, code := range languages {
+			options = append(options, code+" ("+languageNames[code]+")")
+		}
+		response = tr(args.Language, "language.current", "language", languageNames[args.Language], "options", strings.Join(options, ", "))
+	} else if !isSupportedLanguage(requested) {
+		response = tr(args.Language, "language.unknown", "language", args.Text("idioma"))
+	} else {
+		chat := getChatSettings(message.Chat.ID)
+		chat.Language = requested
+		updateChatSettings(chat)
+		response = tr(requested, "language.set", "language", languageNames[requested])
+	}
+	respondToMessage(message, response)
+}
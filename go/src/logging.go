@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// The bot logs with log/slog. Lines about an update carry the chat_id, user_id, command and game_id they concern, so
+// the logs of a chat or a game can be filtered. The bot token never shows up: it is replaced in every value, as the
+// Bot API puts it in its URLs and so in the errors about them.
+
+// logLevel is the level of the bot logs, which changes when the configuration is reloaded.
+var logLevel = new(slog.LevelVar)
+
+const redactedToken = "[token]"
+
+// setupLogging sends the logs, those of the log package included, to output in the configured format.
+func setupLogging(output io.Writer, config *Config) {
+	logLevel.Set(parseLogLevel(config.LogLevel))
+	options := &slog.HandlerOptions{Level: logLevel, ReplaceAttr: redactTokenAttr}
+	var handler slog.Handler = slog.NewTextHandler(output, options)
+	if config.LogFormat == "json" {
+		handler = slog.NewJSONHandler(output, options)
+	}
+	slog.SetDefault(slog.New(handler))
+}
+
+func parseLogLevel(level string) slog.Level {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return slog.LevelInfo
+	}
+	return parsed
+}
+
+// redactTokenAttr hides the bot token in a log value.
+func redactTokenAttr(groups []string, attr slog.Attr) slog.Attr {
+	token := getConfig().Token
+	if token == "" {
+		return attr
+	}
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		if strings.Contains(attr.Value.String(), token) {
+			attr.Value = slog.StringValue(strings.ReplaceAll(attr.Value.String(), token, redactedToken))
+		}
+	case slog.KindAny:
+		if text := fmt.Sprint(attr.Value.Any()); strings.Contains(text, token) {
+			attr.Value = slog.StringValue(strings.ReplaceAll(text, token, redactedToken))
+		}
+	}
+	return attr
+}
+
+// updateLogger returns a logger for the lines about an update, with the chat, user and command it comes from.
+func updateLogger(update tgbotapi.Update) *slog.Logger {
+	switch {
+	case update.Message != nil:
+		return messageLogger(update.Message)
+	case update.CallbackQuery != nil:
+		logger := slog.With("user_id", update.CallbackQuery.From.ID, "callback", update.CallbackQuery.Data)
+		if update.CallbackQuery.Message != nil {
+			logger = logger.With("chat_id", update.CallbackQuery.Message.Chat.ID)
+		}
+		return logger
+	}
+	return slog.Default()
+}
+
+func messageLogger(message *tgbotapi.Message) *slog.Logger {
+	logger := slog.With("chat_id", message.Chat.ID)
+	if message.From != nil {
+		logger = logger.With("user_id", message.From.ID)
+	}
+	if message.IsCommand() {
+		logger = logger.With("command", message.Command())
+	}
+	return logger
+}
+
+// gameLogger returns a logger for the lines about a game.
+func gameLogger(game Game) *slog.Logger {
+	return slog.With("chat_id", game.ChatID, "game_id", game.Id)
+}
+
+// loggingTransport logs every Bot API call at debug level, by method only as the URL holds the token.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (transport loggingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	method := request.URL.Path[strings.LastIndex(request.URL.Path, "/")+1:]
+	start := time.Now()
+	response, err := transport.next.RoundTrip(request)
+	if err != nil {
+		slog.Debug("bot API call failed", "method", method, "duration", time.Since(start), "error", err)
+		return response, err
+	}
+	slog.Debug("bot API call", "method", method, "status", response.StatusCode, "duration", time.Since(start))
+	return response, err
+}
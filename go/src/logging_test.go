@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestLogging(t *testing.T) {
+	previous := slog.Default()
+	defer slog.SetDefault(previous)
+	defer log.SetFlags(log.Flags())
+
+	config := *getConfig()
+	config.LogFormat = "json"
+	config.LogLevel = "info"
+	var output bytes.Buffer
+	setupLogging(&output, &config)
+	defer logLevel.Set(slog.LevelInfo)
+
+	update := tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat:     &tgbotapi.Chat{ID: -5},
+		From:     &ana,
+		Text:     "/anotarme 3",
+		Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Length: 9}},
+	}}
+	updateLogger(update).Info("running command", "error", errors.New("Post https://api.telegram.org/bot"+fakeToken+"/sendMessage: timeout"))
+	updateLogger(update).Debug("hidden")
+	log.Printf("from the log package with %s", fakeToken)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected the debug line to be left out, got:\n%s", output.String())
+	}
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["chat_id"] != float64(-5) || line["user_id"] != float64(ana.ID) || line["command"] != "anotarme" {
+		t.Errorf("expected the chat, user and command in the line, got %v", line)
+	}
+	if strings.Contains(output.String(), fakeToken) {
+		t.Errorf("expected the token to be redacted, got:\n%s", output.String())
+	}
+	assertContains(t, output.String(), "bot"+redactedToken+"/sendMessage", "from the log package with "+redactedToken)
+
+	output.Reset()
+	logLevel.Set(parseLogLevel("debug"))
+	gameLogger(Game{Id: 7, ChatID: -5}).Debug("shown")
+	assertContains(t, output.String(), `"game_id":7`, `"msg":"shown"`)
+}
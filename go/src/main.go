@@ -1,60 +1,132 @@
 package main
 
 import (
-	"log"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 )
 
 // We keep this in memory for now but eventually we have to set up a mariaDB
 
-var commands = getCommands()
-var config *Config
-var bot *tgbotapi.BotAPI
+var commands = indexCommands(getCommands())
+var callbacks = getCallbacks()
+var bot TelegramClient
 
-func init() {
-	var configError error
-	config, configError = getConfig("config.json")
-	checkForFatalError("Error loading config: ", configError)
+// handlers tracks the running handlers, so a replay can wait until an update is fully handled.
+var handlers sync.WaitGroup
+
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "replay":
+			os.Exit(replayCommand(os.Args[2:]))
+		case "games", "players", "export", "import", "backup", "restore":
+			os.Exit(adminCommand(os.Args[1:]))
+		}
+	}
+
+	config, configError := loadConfig(os.Args[1:], os.Getenv)
+	checkForFatalError("error loading the configuration", configError)
+	applyConfig(config)
+	setConfig(config)
+	setupLogging(os.Stderr, config)
+	slog.Info("configuration loaded", "config", config.String())
+
+	if config.Recording != "" {
+		checkForFatalError("error opening the recording", startRecording(config.Recording))
+	}
 
 	var botError error
-	bot, botError = tgbotapi.NewBotAPI(config.Token)
-	checkForFatalError("Error initializing bot: ", botError)
-	bot.Debug = true
-	log.Printf("Connected as %s", bot.Self.UserName)
-}
+	bot, botError = connect(config.Token)
+	checkForFatalError("error connecting to Telegram", botError)
 
-func main() {
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+	publishCommands(bot)
+
+	updates, channelError := openUpdates(bot, config.Webhook)
+	checkForFatalError("error opening the updates channel", channelError)
+
+	go runScheduler()
+	go watchConfig(os.Args[1:])
+	if config.MetricsListen != "" {
+		go serveMetrics(config.MetricsListen)
+	}
+	if config.AdminSocket != "" {
+		go serveAdminSocket(config.AdminSocket)
+	}
 
-	var channelError error
-	updates, channelError := bot.GetUpdatesChan(u)
-	checkForFatalError("Error opening bot channel: ", channelError)
+	serveUpdates(updates)
+}
 
+// serveUpdates dispatches every update to its handler until the channel is closed.
+func serveUpdates(updates tgbotapi.UpdatesChannel) {
 	for update := range updates {
-		if update.Message == nil {
-			continue
+		handleUpdate(update)
+	}
+}
+
+func handleUpdate(update tgbotapi.Update) {
+	activeRecorder.write(RecordEntry{Update: &update})
+	rememberUsers(update)
+	rememberChat(update)
+	logger := updateLogger(update)
+	logger.Debug("update received", "update_id", update.UpdateID)
+	updatesReceived.inc(updateType(update.Message != nil, update.CallbackQuery != nil))
+
+	if update.CallbackQuery != nil {
+		prefix := strings.SplitN(update.CallbackQuery.Data, ":", 2)[0]
+		if callback, ok := callbacks[prefix]; ok {
+			handle(logger, func() { callback(bot, update.CallbackQuery) })
 		}
+		return
+	}
 
-		if update.Message.IsCommand() {
-			command := update.Message.Command()
+	if update.Message == nil {
+		return
+	}
 
-			cmd, ok := commands[command]
+	if update.Message.IsCommand() {
+		command := update.Message.Command()
 
-			if !ok {
-				go handleUnknownCommand(bot, update.Message)
-			} else {
-				go cmd(bot, update.Message)
-			}
+		cmd, ok := commands[command]
 
+		if !ok {
+			commandsHandled.inc("", outcomeUnknown)
+			handle(logger, func() { handleUnknownCommand(bot, update.Message) })
+		} else {
+			handle(logger, func() { runCommand(bot, cmd, update.Message) })
 		}
 
+	} else {
+		handle(logger, func() { handleConversationMessage(bot, update.Message) })
 	}
 }
 
+// handle runs a handler in the background, timing it and logging a panic instead of stopping the bot.
+func handle(logger *slog.Logger, handler func()) {
+	handlers.Add(1)
+	go func() {
+		defer handlers.Done()
+		start := time.Now()
+		defer func() {
+			if problem := recover(); problem != nil {
+				handlerPanics.inc()
+				logger.Error("handler panicked", "panic", problem, "stack", string(debug.Stack()))
+			}
+			handlerDuration.observe(time.Since(start).Seconds())
+			logger.Debug("update handled", "duration", time.Since(start))
+		}()
+		handler()
+	}()
+}
+
 func checkForFatalError(message string, err error) {
 	if err != nil {
-		log.Fatal(message, err)
+		slog.Error(message, "error", err)
+		os.Exit(1)
 	}
 }
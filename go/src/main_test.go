@@ -0,0 +1,158 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+var fake *fakeTelegram
+
+// TestMain runs the bot against the fake Telegram server, without rate limits, for the end to end tests.
+func TestMain(m *testing.M) {
+	fake = newFakeTelegram()
+	client, err := fake.client()
+	if err != nil {
+		log.Fatal("Error connecting to the fake Telegram server: ", err)
+	}
+	bot = client
+	config := defaultConfig()
+	config.Token = fakeToken
+	config.RateLimits = RateLimitConfig{}
+	setConfig(config)
+	initialDeliveryBackoff = time.Millisecond
+
+	updates, err := bot.GetUpdatesChan(tgbotapi.NewUpdate(0))
+	if err != nil {
+		log.Fatal("Error opening the fake updates channel: ", err)
+	}
+	go serveUpdates(updates)
+
+	code := m.Run()
+	fake.close()
+	os.Exit(code)
+}
+
+var ana = tgbotapi.User{ID: 101, FirstName: "Ana", UserName: "ana"}
+var bruno = tgbotapi.User{ID: 102, FirstName: "Bruno"}
+var carla = tgbotapi.User{ID: 103, FirstName: "Carla", LastName: "Paz", UserName: "carla"}
+
+var testChatsMutex sync.Mutex
+var nextTestChatID int64 = -1000
+
+// testChat is a chat where a test talks to the bot. Every test uses its own group so their games do not mix.
+type testChat struct {
+	t    *testing.T
+	chat *tgbotapi.Chat
+}
+
+func newTestGroup(t *testing.T) *testChat {
+	testChatsMutex.Lock()
+	defer testChatsMutex.Unlock()
+	id := nextTestChatID
+	nextTestChatID--
+	return &testChat{t: t, chat: &tgbotapi.Chat{ID: id, Type: "group", Title: "Futbol"}}
+}
+
+func newTestPrivateChat(t *testing.T, user tgbotapi.User) *testChat {
+	return &testChat{t: t, chat: &tgbotapi.Chat{ID: int64(user.ID), Type: "private", FirstName: user.FirstName}}
+}
+
+// send posts a message to the chat, marking the leading /command as Telegram does.
+func (chat *testChat) send(from tgbotapi.User, text string) *tgbotapi.Message {
+	return chat.sendMessage(&tgbotapi.Message{From: &from, Text: text})
+}
+
+// reply posts a message answering another one.
+func (chat *testChat) reply(from tgbotapi.User, to *tgbotapi.Message, text string) *tgbotapi.Message {
+	return chat.sendMessage(&tgbotapi.Message{From: &from, Text: text, ReplyToMessage: to})
+}
+
+// sendMessage injects a message once the handlers of the previous ones are done, so tests see one at a time.
+func (chat *testChat) sendMessage(message *tgbotapi.Message) *tgbotapi.Message {
+	handlers.Wait()
+	message.Chat = chat.chat
+	message.Date = int(time.Now().Unix())
+	if strings.HasPrefix(message.Text, "/") {
+		command := strings.Fields(message.Text)[0]
+		message.Entities = &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+	return fake.inject(tgbotapi.Update{Message: message}).Message
+}
+
+// press taps a button of a message sent by the bot, once the handlers that sent it are done.
+func (chat *testChat) press(from tgbotapi.User, message fakeRequest, data string) {
+	handlers.Wait()
+	fake.inject(tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "callback",
+		From:    &from,
+		Message: &tgbotapi.Message{MessageID: message.MessageID, Chat: chat.chat},
+		Data:    data,
+	}})
+}
+
+// expect waits for the next thing the bot sends, which must go to this chat.
+func (chat *testChat) expect() fakeRequest {
+	chat.t.Helper()
+	request := fake.next(chat.t)
+	if request.Method != "answerCallbackQuery" && request.chatID() != chat.chat.ID {
+		chat.t.Fatalf("expected a message to chat %d, got %s to chat %d: %q", chat.chat.ID, request.Method, request.chatID(), request.text())
+	}
+	return request
+}
+
+// command sends a message and returns the text of the answer, checking that it contains every fragment.
+func (chat *testChat) command(from tgbotapi.User, text string, fragments ...string) string {
+	chat.t.Helper()
+	chat.send(from, text)
+	answer := chat.expect()
+	assertContains(chat.t, answer.text(), fragments...)
+	return answer.text()
+}
+
+// newGame creates a game through /nuevopartido and returns its number.
+func (chat *testChat) newGame(organizer tgbotapi.User, size string) int {
+	chat.t.Helper()
+	chat.command(organizer, "/nuevopartido "+size, "Se ha iniciado un nuevo partido de "+size)
+	return chat.lastGame().Id
+}
+
+// lastGame returns the newest game of the chat.
+func (chat *testChat) lastGame() Game {
+	chat.t.Helper()
+	mutex.Lock()
+	defer mutex.Unlock()
+	var last Game
+	for _, game := range games {
+		if game.ChatID == chat.chat.ID && game.Id > last.Id {
+			last = game
+		}
+	}
+	if last.Id == 0 {
+		chat.t.Fatal("the chat has no games")
+	}
+	return last
+}
+
+func assertContains(t *testing.T, text string, fragments ...string) {
+	t.Helper()
+	for _, fragment := range fragments {
+		if !strings.Contains(text, fragment) {
+			t.Errorf("expected %q in:\n%s", fragment, text)
+		}
+	}
+}
+
+func assertNotContains(t *testing.T, text string, fragments ...string) {
+	t.Helper()
+	for _, fragment := range fragments {
+		if strings.Contains(text, fragment) {
+			t.Errorf("did not expect %q in:\n%s", fragment, text)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// WaitlistEntry is someone waiting for the priority window of a game to end, either a player or a guest they invited.
+type WaitlistEntry struct {
+	PlayerID  int
+	GuestName string
+}
+
+func (entry WaitlistEntry) isGuest() bool {
+	return entry.GuestName != ""
+}
+
+func (game Game) isFull() bool {
+	return game.MaxPlayers <= len(game.Players)+len(game.Guests)
+}
+
+func (game Game) inPriorityWindow(now time.Time) bool {
+	return now.Before(game.PriorityUntil)
+}
+
+func (game Game) isWaiting(playerID int) bool {
+	for _, entry := range game.Waitlist {
+		if !entry.isGuest() && entry.PlayerID == playerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (game Game) waitingGuestsInvitedBy(playerID int) int {
+	count := 0
+	for _, entry := range game.Waitlist {
+		if entry.isGuest() && entry.PlayerID == playerID {
+			count++
+		}
+	}
+	return count
+}
+
+func (game Game) hasWaitingGuestNamed(name string) bool {
+	for _, entry := range game.Waitlist {
+		if entry.isGuest() && strings.EqualFold(entry.GuestName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// admitWaitlist moves the waitlist into the game in arrival order, while there is room left.
+func admitWaitlist(game *Game) (admitted []WaitlistEntry, leftOut []WaitlistEntry) {
+	for _, entry := range game.Waitlist {
+		if !entry.isGuest() && contains(game.Players, entry.PlayerID) {
+			continue
+		}
+		if game.isFull() {
+			leftOut = append(leftOut, entry)
+			continue
+		}
+		if entry.isGuest() {
+			game.Guests = append(game.Guests, Guest{Name: entry.GuestName, InviterID: entry.PlayerID})
+		} else {
+			game.Players = append(game.Players, entry.PlayerID)
+		}
+		admitted = append(admitted, entry)
+	}
+	game.Waitlist = make([]WaitlistEntry, 0)
+	game.PriorityUntil = time.Time{}
+	return admitted, leftOut
+}
+
+// closePriorityWindows admits the waitlist of every game whose priority window is over and lets the chat know.
+func closePriorityWindows(now time.Time) {
+	mutex.Lock()
+	closed := make([]Game, 0)
+	results := make([][2][]WaitlistEntry, 0)
+	for id, game := range games {
+		if game.PriorityUntil.IsZero() || game.inPriorityWindow(now) {
+			continue
+		}
+		admitted, leftOut := admitWaitlist(&game)
+		games[id] = game
+		if game.Active && len(admitted)+len(leftOut) > 0 {
+			closed = append(closed, game)
+			results = append(results, [2][]WaitlistEntry{admitted, leftOut})
+		}
+	}
+	mutex.Unlock()
+
+	for i, game := range closed {
+		language := chatLanguage(game.ChatID)
+		response := tr(language, "waitlist.closed", "game", game.Id)
+		if len(results[i][0]) > 0 {
+			response += "\n" + tr(language, "waitlist.admitted", "names", HTML(describeWaitlist(game.ChatID, results[i][0])))
+		}
+		if len(results[i][1]) > 0 {
+			response += "\n" + tr(language, "waitlist.left_out", "names", HTML(describeWaitlist(game.ChatID, results[i][1])))
+		}
+		sendMessage(game.ChatID, response)
+	}
+}
+
+func describeWaitlist(chatID int64, entries []WaitlistEntry) string {
+	return waitlistNames(chatLanguage(chatID), entries, chatPlayerNames(chatID))
+}
+
+func waitlistNames(language string, entries []WaitlistEntry, players PlayerNames) string {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.isGuest() {
+			names = append(names, tr(language, "guest.invited_by", "guest", entry.GuestName, "inviter", players.Handle(entry.PlayerID)))
+		} else {
+			names = append(names, escapeHTML(players.Name(entry.PlayerID)))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func handleSociosCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	chat := getChatSettings(message.Chat.ID)
+	language := args.Language
+	name := message.From.FirstName
+	var response string
+
+	action := args.Text("opcion")
+	target := args.User
+	// Members get a head start on every game, so only the administrators of the group choose them. Anybody can leave.
+	canManage := func() bool { return isChatAdmin(message.Chat, message.From.ID) }
+
+	switch {
+	case action == "":
+		if len(chat.Members) == 0 {
+			response = tr(language, "members.empty")
+		} else {
+			response = tr(language, "members.list") + "\n"
+			for i, memberID := range chat.Members {
+				response += strconv.Itoa(i+1) + ". " + escapeHTML(getPlayerName(bot, message.Chat.ID, memberID)) + "\n"
+			}
+			if chat.PriorityWindow > 0 {
+				response += "\n" + trn(language, "members.window", int(chat.PriorityWindow.Minutes()))
+			} else {
+				response += "\n" + tr(language, "members.no_window")
+			}
+		}
+	case isOption(language, action, "option.add"):
+		if !canManage() {
+			response = tr(language, "members.add_forbidden", "name", name)
+		} else if chat.isMember(target.ID) {
+			response = tr(language, "members.already", "member", target.FirstName)
+		} else {
+			chat.Members = append(chat.Members, target.ID)
+			updateChatSettings(chat)
+			response = tr(language, "members.added", "member", target.FirstName)
+		}
+	case isOption(language, action, "option.remove"):
+		if target.ID != message.From.ID && !canManage() {
+			response = tr(language, "members.remove_forbidden", "name", name)
+		} else if !chat.isMember(target.ID) {
+			response = tr(language, "members.not_member", "member", target.FirstName)
+		} else {
+			chat.Members = remove(chat.Members, target.ID)
+			updateChatSettings(chat)
+			response = tr(language, "members.removed", "member", target.FirstName)
+		}
+	case isOption(language, action, "option.window"):
+		if !canManage() {
+			response = tr(language, "members.window_forbidden", "name", name)
+		} else if !args.Has("minutos") {
+			response = tr(language, "members.window_missing", "name", name)
+		} else {
+			minutes := args.Number("minutos")
+			chat.PriorityWindow = time.Duration(minutes) * time.Minute
+			updateChatSettings(chat)
+			if minutes == 0 {
+				response = tr(language, "members.window_disabled")
+			} else {
+				response = trn(language, "members.window_set", minutes)
+			}
+		}
+	default:
+		response = tr(language, "members.unknown_option", "option", action, "name", name)
+	}
+
+	respondToMessage(message, response)
+}
@@ -0,0 +1,270 @@
+package main
+
+var messagesEN = map[string]string{
+	// Members and priority window
+	"waitlist.closed":          "The members priority for game {game} is over.",
+	"waitlist.admitted":        "Joined: {names}.",
+	"waitlist.left_out":        "Left out because the game is full: {names}.",
+	"guest.invited_by":         "{guest} (guest of {inviter})",
+	"option.add":               "add",
+	"option.remove":            "remove",
+	"option.window":            "window",
+	"members.empty":            "There are no members in this group yet. An administrator can join with /members add, or reply to someone's message with /members add to add them.",
+	"members.list":             "Group members:",
+	"members.window.one":       "Members have priority for {count} minute after a game is created.",
+	"members.window.other":     "Members have priority for {count} minutes after a game is created.",
+	"members.no_window":        "There is no priority window. Use /members window [minutes] to set one.",
+	"members.add_forbidden":    "Only the administrators of the group can add members, @{name}.",
+	"members.already":          "{member} is already a member.",
+	"members.added":            "{member} is now a member of the group.",
+	"members.remove_forbidden": "Only the administrators of the group can remove other members, @{name}.",
+	"members.not_member":       "{member} is not a member.",
+	"members.removed":          "{member} is no longer a member of the group.",
+	"members.window_forbidden": "Only the administrators of the group can change the priority window, @{name}.",
+	"members.window_missing":   "@{name} you must give the priority minutes! Example: /members window [minutes]",
+	"members.window_disabled":  "The members priority was turned off.",
+	"members.window_set.one":   "Members will have {count} minute of priority in the next games.",
+	"members.window_set.other": "Members will have {count} minutes of priority in the next games.",
+	"members.unknown_option":   "I don't know the option {option}, @{name}. The options are: /members, /members add, /members remove and /members window [minutes]",
+
+	// Venues
+	"venues.address_missing": "@{name} you must give the address of the venue! Example: /addvenue [name] [address] | [notes]",
+	"venues.updated":         "The venue {venue} was updated.",
+	"venues.saved":           "The venue {venue} was saved. You can use it with /setaddress [game number] {venue}",
+	"venues.empty":           "There are no saved venues. You can add one with /addvenue [name] [address]",
+	"venues.list":            "Saved venues:",
+	"venues.not_found":       "There is no saved venue named {venue}.",
+	"venues.removed":         "The venue {venue} was removed.",
+
+	// Date polls
+	"poll.title":             "Date poll for game {game} (closes on {date} at {time}):",
+	"poll.option.one":        "{option}: {count} vote",
+	"poll.option.other":      "{option}: {count} votes",
+	"poll.already_open":      "There is already an open poll for game {game}. You can close it with /closepoll {game}",
+	"poll.options_missing":   "@{name} you must give between 2 and 10 options separated by |. Example: /datepoll [game number] tuesday 21:00 | thursday 20:00",
+	"poll.send_failed":       "The poll could not be created: {error}",
+	"poll.not_open":          "Game {game} has no open poll.",
+	"poll.closed":            "The poll is already closed.",
+	"poll.voted":             "You voted {option}",
+	"poll.closed_footer":     "Poll closed.",
+	"poll.winner.one":        "The poll for game {game} is closed. The chosen date is {option} with {count} vote.",
+	"poll.winner.other":      "The poll for game {game} is closed. The chosen date is {option} with {count} votes.",
+	"poll.deadline_too_long": "@{name} a poll can last at most {days} days.",
+	"poll.no_votes":          "The poll for game {game} closed without votes, the date stays the same.",
+
+	// Weekly games
+	"weekday.0":                      "sunday",
+	"weekday.1":                      "monday",
+	"weekday.2":                      "tuesday",
+	"weekday.3":                      "wednesday",
+	"weekday.4":                      "thursday",
+	"weekday.5":                      "friday",
+	"weekday.6":                      "saturday",
+	"option.delete":                  "delete",
+	"option.repeat":                  "repeat",
+	"option.ahead":                   "ahead",
+	"recurring.every":                "Every {day} at {time}, {size} a side",
+	"recurring.at":                   "at {address}",
+	"recurring.days_ahead.one":       "Created {count} day before",
+	"recurring.days_ahead.other":     "Created {count} days before",
+	"recurring.keeps_regulars":       "and last week's players are signed up",
+	"recurring.created":              "The weekly game of {date} was created: game {game}.",
+	"recurring.regulars_added.one":   "{count} player from last week is already signed up.",
+	"recurring.regulars_added.other": "{count} players from last week are already signed up.",
+	"recurring.join":                 "You can join with /join {game}",
+	"recurring.empty":                "There are no weekly games. You can create one with /weekly [day] [time] [size] [address]. Example: /weekly tuesday 21:00 5",
+	"recurring.list":                 "Weekly games:",
+	"recurring.number_missing":       "@{name} you must give the number of the weekly game! Example: /weekly {option} [number]",
+	"recurring.not_found":            "There is no weekly game with that number, @{name}.",
+	"recurring.forbidden":            "Only whoever created the weekly game can change it.",
+	"recurring.deleted":              "The weekly game {recurring} was deleted. Games already created are not cancelled.",
+	"recurring.repeat_on":            "Each week's players will be signed up for the next game.",
+	"recurring.repeat_off":           "Last week's players will no longer be signed up automatically.",
+	"recurring.days_missing":         "@{name} you must say how many days ahead the game is created! Example: /weekly ahead [number] [days]",
+	"recurring.invalid_days":         "{days} is not a valid number of days. It must be a number between 1 and 6.",
+	"recurring.days_set.one":         "The weekly game {recurring} will be created {count} day before it is played.",
+	"recurring.days_set.other":       "The weekly game {recurring} will be created {count} days before it is played.",
+	"recurring.params_missing":       "To create a weekly game @{name}, you must give the day, the time and the size. Example: /weekly tuesday 21:00 5 [address]",
+	"recurring.invalid_weekday":      "{day} is not a valid day of the week.",
+	"recurring.error":                "Error creating the weekly game: {error}",
+	"recurring.saved":                "The weekly game was created:\n{recurring}\nYou can change how many days ahead it is created with /weekly ahead {id} [days] and sign up last week's players with /weekly repeat {id}",
+	"time.invalid":                   "{time} is not a valid time.",
+
+	// Game creation wizard
+	"wizard.size":           "@{name}, how many players per team?",
+	"wizard.date":           "@{name}, what day is the game?",
+	"wizard.schedule":       "@{name}, at what time?",
+	"wizard.venue":          "@{name}, where is it played? Pick a saved venue or type the address.",
+	"wizard.hint":           "You can tap an option or reply to this message.",
+	"wizard.back":           "Back",
+	"wizard.skip":           "No address",
+	"wizard.cancel":         "Cancel",
+	"wizard.not_understood": "I didn't understand the answer.",
+	"wizard.join":           "I'm in",
+	"wizard.not_yours":      "Only whoever is creating the game can choose.",
+	"wizard.cancelled":      "The game creation was cancelled, @{name}.",
+	"wizard.unavailable":    "The game is no longer available.",
+	"wizard.expired":        "The game creation was cancelled for inactivity, @{name}. You can start again with /newgame",
+
+	// Games
+	"game.title":          "Game {game}:",
+	"game.date":           "Date: {date}",
+	"game.schedule":       "Time: {schedule}",
+	"game.address":        "Address: {address}",
+	"game.players":        "Players:",
+	"game.total":          "Total players: {count}/{max}",
+	"game.waitlist":       "Waitlist (members have priority until {time}):",
+	"game.cost":           "Cost: {cost} ({share} per player)",
+	"game.all_paid":       "Everybody paid.",
+	"game.unpaid":         "Still to pay:",
+	"game.cancelled":      "The game was cancelled by @{name}.",
+	"games.none":          "There are no pending games, @{name}. You can start a new one with /newgame",
+	"games.title":         "Upcoming games:",
+	"games.item":          "Game {game}, Players: {players}/{max}",
+	"games.total.one":     "{count} game in total.",
+	"games.total.other":   "{count} games in total.",
+	"games.more":          "You can use /game [game number] for more information.",
+	"newgame.error":       "Error creating the game: {error}",
+	"newgame.created":     "A new {size} a side game was started. You can join it with /join {game}",
+	"newgame.priority":    "Members have priority until {time}, everybody else goes to the waitlist until then.",
+	"error.invalid_size":  "The given size is not valid. It must be a number between 1 and {max}.",
+	"address.added":       "The address was added to game {game}.",
+	"address.venue_added": "The venue {venue} was added to game {game}.",
+	"schedule.added":      "The time was added to game {game}.",
+	"date.added":          "The date was added to game {game}.",
+
+	// Joining and leaving
+	"join.already_waiting": "You are already in the waitlist @{name}.",
+	"join.waitlisted":      "@{name} members have priority until {time}. You are in the waitlist and will join if there is room when it ends.",
+	"join.joined":          "Hi @{name}! You joined the game. Good luck!",
+	"join.already":         "You are already in the game @{name}. Let's play!",
+	"join.full":            "The game is full @{name}, you can't join",
+	"option.keep":          "keep",
+	"leave.waitlist":       "You left the waitlist, @{name}.",
+	"leave.not_playing":    "You can't leave, @{name}. You are not in the game.",
+	"leave.done":           "You left the game, @{name}.",
+	"leave.guests_kept":    "Your guests ({guests}) are now in charge of the organizer.",
+	"leave.guests_removed": "Your guests left too: {guests}.",
+
+	// Guests
+	"guests.not_found":        "{guest} can't be removed. They are not in the game.",
+	"guests.remove_forbidden": "Only whoever invited {guest} or the organizer of the game can remove them.",
+	"guests.removed":          "@{name} you removed {guest}.",
+	"guests.duplicate":        "There is already a guest named {guest} in the game, @{name}. Try another name, for example adding the last name.",
+	"guests.limit.one":        "@{name} you already invited {count} player to this game, you can't invite more.",
+	"guests.limit.other":      "@{name} you already invited {count} players to this game, you can't invite more.",
+	"guests.waitlisted":       "@{name} members have priority until {time}. {guest} is in the waitlist and will join if there is room when it ends.",
+	"guests.added":            "@{name} you invited {guest} to the game.",
+	"guests.full":             "The game is full @{name}, you can't invite {guest}",
+
+	// Payments
+	"cost.set":    "A cost of {cost} was set for game {game}.",
+	"cost.share":  "For now it is {share} per player.",
+	"pay.no_cost": "Game {game} has no cost yet, @{name}.",
+	"pay.nothing": "You have nothing to pay in this game, @{name}.",
+	"pay.already": "You had already marked your payment, @{name}.",
+	"pay.done":    "Thanks @{name}, your payment of {amount} was recorded.",
+	"debts.none":  "There are no pending debts.",
+	"debts.title": "Pending debts:",
+	"debts.total": "Total owed: {amount}.",
+
+	// Languages
+	"language.current": "The language of this chat is {language}. You can change it with /language [language], the options are: {options}",
+	"language.unknown": "I don't know the language {language}. Use /language to see the options.",
+	"language.set":     "Done, I now speak {language} in this chat.",
+
+	// Commands
+	"command.unknown":         "Unknown command. Use /help to see the list of available commands.",
+	"command.error":           "Sorry, something went wrong while processing the command.",
+	"player.unknown":          "Player {id}",
+	"args.user_missing":       "@{name}, to use /{command} you must reply to the message of the {argument}. Example: {usage}",
+	"args.missing":            "@{name}, to use /{command} you must give the {argument}. Example: {usage}",
+	"args.invalid_game":       "{value} is not a valid game number.",
+	"args.game_not_found":     "There is no pending game with that number, @{name}. You can start a new one with /newgame",
+	"args.invalid_number":     "{value} is not a valid value for {argument}.",
+	"args.invalid_duration":   "{value} is not a valid value for {argument}, use hours or minutes like 48h or 90m.",
+	"args.organizer_only":     "Only the organizer of the game can use /{command}.",
+	"arg.numero de partido":   "game number",
+	"arg.tamaño":              "size",
+	"arg.fecha":               "date",
+	"arg.horario":             "time",
+	"arg.direccion o cancha":  "address or venue",
+	"arg.mantener":            "keep",
+	"arg.nombre":              "name",
+	"arg.monto":               "amount",
+	"arg.opcion":              "option",
+	"arg.jugador":             "player",
+	"arg.minutos":             "minutes",
+	"arg.dia":                 "day",
+	"arg.direccion":           "address",
+	"arg.direccion | notas":   "address | notes",
+	"arg.opcion 1 | opcion 2": "option 1 | option 2",
+	"arg.idioma":              "language",
+	"arg.plazo":               "deadline",
+	"help.title":              "The available commands are:",
+	"help.yojuego":            "Join a game",
+	"help.verpartido":         "Shows the information of a game",
+	"help.verpartidos":        "Shows the information of every game",
+	"help.nuevopartido":       "Starts a new game. Without a size it asks step by step for the size, date, time and venue",
+	"help.agregarfecha":       "Sets the date of a game",
+	"help.agregarhorario":     "Sets the time of a game",
+	"help.agregardireccion":   "Sets an address or a saved venue for a game",
+	"help.cancelarpartido":    "Cancels a game, only whoever created it can cancel it",
+	"help.darsedebaja":        "Leave a game. With keep your guests stay in charge of the organizer",
+	"help.agregarinvitado":    "Invite a guest to a game",
+	"help.bajarinvitado":      "Remove a guest from a game, only whoever invited them or the organizer can do it",
+	"help.costo":              "Sets the cost of a game to split it among the players",
+	"help.pague":              "Marks that you paid your share of a game",
+	"help.deudas":             "Shows what each player owes",
+	"help.socios":             "Shows the members of the group. Options: add, remove (replying to a message of the player) and window with the priority minutes, only for administrators",
+	"help.partidofijo":        "Creates a game that repeats every week. Without parameters it shows the weekly games",
+	"help.agregarcancha":      "Saves a venue. Replying to a location saves the map",
+	"help.canchas":            "Shows the saved venues",
+	"help.borrarcancha":       "Removes a saved venue",
+	"help.votarfecha":         "Starts a poll to choose the date of a game. It closes in 24 hours or after the deadline you give, like 48h",
+	"help.cerrarvotacion":     "Closes the date poll early",
+	"help.ayuda":              "Shows the list of available commands",
+	"help.idioma":             "Shows or changes the language of the bot in this chat, only for administrators",
+
+	// Templates
+	"option.template_roster":   "roster",
+	"option.template_list":     "list",
+	"option.template_creation": "creation",
+	"option.template_reminder": "reminder",
+	"option.reset":             "reset",
+	"templates.list":           "Message templates. Use /template [name] to see one, /template [name] [template] to change it and /template [name] reset to go back to the original:",
+	"templates.custom":         "(customized)",
+	"templates.fields":         "Templates use text/template and can show: {fields}. They also have the functions t, plural and add, and {{template \"detalles\" .Game}} shows the date, time and address. The reminder is sent when a weekly game is created.",
+	"templates.unknown":        "There is no template named {template}. Use /template to see the templates.",
+	"templates.current":        "Template {template}:",
+	"templates.preview":        "This is how it looks with a sample game:",
+	"templates.reset":          "The template {template} was reset.",
+	"templates.invalid":        "The template is not valid: {error}",
+	"templates.saved":          "The template {template} was saved. This is how it looks with a sample game:",
+	"args.admin_only":          "Only the administrators of the group can use /{command}.",
+	"arg.plantilla":            "template",
+	"help.plantilla":           "Shows or changes the message templates of the group, only for administrators",
+
+	// Export
+	"arg.formato":           "format",
+	"help.exportar":         "Sends you in a private chat a file with the games, players, guests and venues of the group, in json or csv, only for administrators",
+	"export.caption":        "Games and venues of the group, in version {version} of the FulBot export format.",
+	"export.unknown_format": "I do not know the format {format}, the options are: {options}",
+	"export.sent":           "@{name} I sent you the file in a private chat.",
+	"export.private_failed": "@{name} I could not send you the file in a private chat, send me a private message first and ask again.",
+
+	// My games
+	"help.mispartidos":         "Shows your upcoming games in every group, with a button to leave each one. Use it in a private chat with the bot",
+	"mygames.private_only":     "@{name} send me /mygames in a private chat and I will show you your games in every group.",
+	"mygames.none":             "You are not signed up for any pending game, @{name}.",
+	"mygames.title":            "Your upcoming games, @{name}:",
+	"mygames.item":             "Game {game} in {chat}, Players: {players}/{max}",
+	"mygames.with_guests":      "You bring your guests: {guests}",
+	"mygames.only_guests":      "You are not playing, but you invited: {guests}",
+	"mygames.leave":            "Leave game {game}",
+	"mygames.not_playing":      "You are no longer in game {game}.",
+	"mygames.done":             "You left game {game}.",
+	"mygames.left":             "@{name} left game {game}.",
+	"mygames.left_with_guests": "@{name} left game {game} along with their guests: {guests}.",
+	"mygames.guests_left":      "@{name} removed their guests from game {game}: {guests}.",
+}
@@ -0,0 +1,270 @@
+package main
+
+var messagesES = map[string]string{
+	// Members and priority window
+	"waitlist.closed":          "Termino la prioridad para socios del partido {game}.",
+	"waitlist.admitted":        "Se sumaron: {names}.",
+	"waitlist.left_out":        "Quedaron afuera porque el partido se lleno: {names}.",
+	"guest.invited_by":         "{guest} (invitado de {inviter})",
+	"option.add":               "agregar",
+	"option.remove":            "quitar",
+	"option.window":            "ventana",
+	"members.empty":            "Todavia no hay socios en este grupo. Un administrador puede sumarse con /socios agregar, o responder al mensaje de alguien con /socios agregar para sumarlo.",
+	"members.list":             "Socios del grupo:",
+	"members.window.one":       "Los socios tienen prioridad durante {count} minuto despues de crear un partido.",
+	"members.window.other":     "Los socios tienen prioridad durante {count} minutos despues de crear un partido.",
+	"members.no_window":        "No hay ventana de prioridad configurada. Usa /socios ventana [minutos] para activarla.",
+	"members.add_forbidden":    "Solo los administradores del grupo pueden sumar socios, @{name}.",
+	"members.already":          "{member} ya es socio.",
+	"members.added":            "{member} ahora es socio del grupo.",
+	"members.remove_forbidden": "Solo los administradores del grupo pueden quitar a otros socios, @{name}.",
+	"members.not_member":       "{member} no es socio.",
+	"members.removed":          "{member} ya no es socio del grupo.",
+	"members.window_forbidden": "Solo los administradores del grupo pueden cambiar la ventana de prioridad, @{name}.",
+	"members.window_missing":   "@{name} debes indicar los minutos de prioridad! Ejemplo: /socios ventana [minutos]",
+	"members.window_disabled":  "Se desactivo la prioridad para socios.",
+	"members.window_set.one":   "Los socios tendran {count} minuto de prioridad en los proximos partidos.",
+	"members.window_set.other": "Los socios tendran {count} minutos de prioridad en los proximos partidos.",
+	"members.unknown_option":   "No conozco la opcion {option}, @{name}. Las opciones son: /socios, /socios agregar, /socios quitar y /socios ventana [minutos]",
+
+	// Venues
+	"venues.address_missing": "@{name} debes agregar la direccion de la cancha! Ejemplo: /agregarcancha [nombre] [direccion] | [notas]",
+	"venues.updated":         "Se actualizo la cancha {venue}.",
+	"venues.saved":           "Se guardo la cancha {venue}. Puedes usarla con /agregardireccion [numero de partido] {venue}",
+	"venues.empty":           "No hay canchas guardadas. Puedes agregar una con /agregarcancha [nombre] [direccion]",
+	"venues.list":            "Canchas guardadas:",
+	"venues.not_found":       "No hay una cancha guardada con el nombre {venue}.",
+	"venues.removed":         "Se borro la cancha {venue}.",
+
+	// Date polls
+	"poll.title":             "Votacion de fecha para el partido {game} (cierra el {date} a las {time}):",
+	"poll.option.one":        "{option}: {count} voto",
+	"poll.option.other":      "{option}: {count} votos",
+	"poll.already_open":      "Ya hay una votacion abierta para el partido {game}. Puedes cerrarla con /cerrarvotacion {game}",
+	"poll.options_missing":   "@{name} debes proporcionar entre 2 y 10 opciones separadas por |. Ejemplo: /votarfecha [numero de partido] martes 21:00 | jueves 20:00",
+	"poll.send_failed":       "No se pudo crear la votacion: {error}",
+	"poll.not_open":          "El partido {game} no tiene una votacion abierta.",
+	"poll.closed":            "La votacion ya esta cerrada.",
+	"poll.voted":             "Votaste {option}",
+	"poll.closed_footer":     "Votacion cerrada.",
+	"poll.winner.one":        "Se cerro la votacion del partido {game}. La fecha elegida es {option} con {count} voto.",
+	"poll.winner.other":      "Se cerro la votacion del partido {game}. La fecha elegida es {option} con {count} votos.",
+	"poll.deadline_too_long": "@{name} la votacion puede durar como mucho {days} dias.",
+	"poll.no_votes":          "Se cerro la votacion del partido {game} sin votos, la fecha no cambia.",
+
+	// Weekly games
+	"weekday.0":                      "domingo",
+	"weekday.1":                      "lunes",
+	"weekday.2":                      "martes",
+	"weekday.3":                      "miercoles",
+	"weekday.4":                      "jueves",
+	"weekday.5":                      "viernes",
+	"weekday.6":                      "sabado",
+	"option.delete":                  "borrar",
+	"option.repeat":                  "repetir",
+	"option.ahead":                   "anticipacion",
+	"recurring.every":                "Todos los {day} a las {time}, partido de {size}",
+	"recurring.at":                   "en {address}",
+	"recurring.days_ahead.one":       "Se crea {count} dia antes",
+	"recurring.days_ahead.other":     "Se crea {count} dias antes",
+	"recurring.keeps_regulars":       "y se anotan los jugadores de la semana anterior",
+	"recurring.created":              "Se creo el partido fijo del {date}: partido {game}.",
+	"recurring.regulars_added.one":   "Ya quedo anotado {count} jugador de la semana pasada.",
+	"recurring.regulars_added.other": "Ya quedaron anotados {count} jugadores de la semana pasada.",
+	"recurring.join":                 "Puedes unirte con /yojuego {game}",
+	"recurring.empty":                "No hay partidos fijos. Puedes crear uno con /partidofijo [dia] [horario] [tamaño] [direccion]. Ejemplo: /partidofijo martes 21:00 5",
+	"recurring.list":                 "Partidos fijos:",
+	"recurring.number_missing":       "@{name} debes indicar el numero del partido fijo! Ejemplo: /partidofijo {option} [numero]",
+	"recurring.not_found":            "No hay un partido fijo con ese numero, @{name}.",
+	"recurring.forbidden":            "Solo quien creo el partido fijo puede modificarlo.",
+	"recurring.deleted":              "Se borro el partido fijo {recurring}. Los partidos ya creados no se cancelan.",
+	"recurring.repeat_on":            "Los jugadores de cada semana quedaran anotados en el partido siguiente.",
+	"recurring.repeat_off":           "Ya no se anotaran automaticamente los jugadores de la semana anterior.",
+	"recurring.days_missing":         "@{name} debes indicar con cuantos dias de anticipacion se crea el partido! Ejemplo: /partidofijo anticipacion [numero] [dias]",
+	"recurring.invalid_days":         "{days} no es una cantidad de dias valida. Debe ser un número entre 1 y 6.",
+	"recurring.days_set.one":         "El partido fijo {recurring} se creara {count} dia antes de jugarse.",
+	"recurring.days_set.other":       "El partido fijo {recurring} se creara {count} dias antes de jugarse.",
+	"recurring.params_missing":       "Para crear un partido fijo @{name}, debes proporcionar el dia, el horario y el tamaño. Ejemplo: /partidofijo martes 21:00 5 [direccion]",
+	"recurring.invalid_weekday":      "{day} no es un dia de la semana valido.",
+	"recurring.error":                "Error al crear el partido fijo: {error}",
+	"recurring.saved":                "Se creo el partido fijo:\n{recurring}\nPuedes cambiar la anticipacion con /partidofijo anticipacion {id} [dias] y anotar a los de la semana anterior con /partidofijo repetir {id}",
+	"time.invalid":                   "{time} no es un horario valido.",
+
+	// Game creation wizard
+	"wizard.size":           "@{name}, ¿de cuantos jugadores por equipo es el partido?",
+	"wizard.date":           "@{name}, ¿que dia se juega?",
+	"wizard.schedule":       "@{name}, ¿a que hora?",
+	"wizard.venue":          "@{name}, ¿donde se juega? Elegi una cancha guardada o escribi la direccion.",
+	"wizard.hint":           "Podes tocar una opcion o responder a este mensaje.",
+	"wizard.back":           "Atras",
+	"wizard.skip":           "Sin direccion",
+	"wizard.cancel":         "Cancelar",
+	"wizard.not_understood": "No entendi la respuesta.",
+	"wizard.join":           "Yo juego",
+	"wizard.not_yours":      "Solo quien esta creando el partido puede elegir.",
+	"wizard.cancelled":      "Se cancelo la creacion del partido, @{name}.",
+	"wizard.unavailable":    "El partido ya no esta disponible.",
+	"wizard.expired":        "Se cancelo la creacion del partido por inactividad, @{name}. Puedes empezar de nuevo con /nuevopartido",
+
+	// Games
+	"game.title":          "Partido {game}:",
+	"game.date":           "Fecha: {date}",
+	"game.schedule":       "Horario: {schedule}",
+	"game.address":        "Direccion: {address}",
+	"game.players":        "Jugadores:",
+	"game.total":          "Total de jugadores: {count}/{max}",
+	"game.waitlist":       "Lista de espera (prioridad para socios hasta las {time}):",
+	"game.cost":           "Costo: {cost} ({share} por jugador)",
+	"game.all_paid":       "Ya pagaron todos.",
+	"game.unpaid":         "Faltan pagar:",
+	"game.cancelled":      "El partido ha sido cancelado por @{name}.",
+	"games.none":          "No hay partidos pendientes, @{name}. Puedes iniciar uno nuevo con /nuevopartido",
+	"games.title":         "Proximos partidos:",
+	"games.item":          "Partido {game}, Jugadores: {players}/{max}",
+	"games.total.one":     "Total de partidos: {count}.",
+	"games.total.other":   "Total de partidos: {count}.",
+	"games.more":          "Puedes usar /verpartido [numero de partido] para mas informacion.",
+	"newgame.error":       "Error al crear nuevo partido: {error}",
+	"newgame.created":     "Se ha iniciado un nuevo partido de {size}. Puedes unirte al partido con el comando /yojuego {game}",
+	"newgame.priority":    "Los socios tienen prioridad hasta las {time}, el resto queda en lista de espera hasta entonces.",
+	"error.invalid_size":  "El tamaño especificado no es válido. Debe ser un número entre 1 y {max}.",
+	"address.added":       "Se ha agregado la dirección al partido {game}.",
+	"address.venue_added": "Se ha agregado la cancha {venue} al partido {game}.",
+	"schedule.added":      "Se ha agregado el horario al partido {game}.",
+	"date.added":          "Se ha agregado la fecha al partido {game}.",
+
+	// Joining and leaving
+	"join.already_waiting": "Ya estás en la lista de espera @{name}.",
+	"join.waitlisted":      "@{name} los socios tienen prioridad hasta las {time}. Quedas en lista de espera y te sumamos si hay lugar cuando termine.",
+	"join.joined":          "¡Hola @{name}! Te has unido al partido. ¡Buena suerte!",
+	"join.already":         "Ya estás en el partido @{name}. ¡A jugar!",
+	"join.full":            "El partido esta completo @{name}, no te podes anotar",
+	"option.keep":          "mantener",
+	"leave.waitlist":       "Saliste de la lista de espera, @{name}.",
+	"leave.not_playing":    "No es posible darse de baja, @{name}. No te encontras en el partido.",
+	"leave.done":           "Te has dado de baja, @{name}.",
+	"leave.guests_kept":    "Tus invitados ({guests}) quedan a cargo del organizador.",
+	"leave.guests_removed": "Tambien se dieron de baja tus invitados: {guests}.",
+
+	// Guests
+	"guests.not_found":        "No es posible dar de baja a {guest}. No se encuentra en el partido.",
+	"guests.remove_forbidden": "Solo quien invito a {guest} o el organizador del partido pueden darlo de baja.",
+	"guests.removed":          "@{name} diste de baja a {guest}.",
+	"guests.duplicate":        "Ya hay un invitado llamado {guest} en el partido, @{name}. Proba con otro nombre, por ejemplo agregando el apellido.",
+	"guests.limit.one":        "@{name} ya invitaste {count} jugador a este partido, no podes invitar mas.",
+	"guests.limit.other":      "@{name} ya invitaste {count} jugadores a este partido, no podes invitar mas.",
+	"guests.waitlisted":       "@{name} los socios tienen prioridad hasta las {time}. {guest} queda en lista de espera y se suma si hay lugar cuando termine.",
+	"guests.added":            "@{name} has invitado a {guest} al partido.",
+	"guests.full":             "El partido esta completo @{name}, no podes invitar a {guest}",
+
+	// Payments
+	"cost.set":    "Se ha cargado un costo de {cost} al partido {game}.",
+	"cost.share":  "Por ahora son {share} por jugador.",
+	"pay.no_cost": "El partido {game} todavia no tiene un costo cargado, @{name}.",
+	"pay.nothing": "No tenes nada que pagar en este partido, @{name}.",
+	"pay.already": "Ya habias marcado tu pago, @{name}.",
+	"pay.done":    "Gracias @{name}, se registro tu pago de {amount}.",
+	"debts.none":  "No hay deudas pendientes.",
+	"debts.title": "Deudas pendientes:",
+	"debts.total": "Total adeudado: {amount}.",
+
+	// Languages
+	"language.current": "El idioma de este chat es {language}. Puedes cambiarlo con /idioma [idioma], las opciones son: {options}",
+	"language.unknown": "No conozco el idioma {language}. Usa /idioma para ver las opciones.",
+	"language.set":     "Listo, ahora hablo {language} en este chat.",
+
+	// Commands
+	"command.unknown":         "Comando desconocido. Usa /ayuda para ver la lista de comandos disponibles.",
+	"command.error":           "Lo siento, ocurrio un error al intentar procesar el comando.",
+	"player.unknown":          "Jugador {id}",
+	"args.user_missing":       "@{name}, para usar /{command} debes responder al mensaje de {argument}. Ejemplo: {usage}",
+	"args.missing":            "@{name}, para usar /{command} debes proporcionar {argument}. Ejemplo: {usage}",
+	"args.invalid_game":       "{value} no es un numero de partido valido.",
+	"args.game_not_found":     "No hay un partido pendiente con ese numero, @{name}. Puedes iniciar uno nuevo con /nuevopartido",
+	"args.invalid_number":     "{value} no es un valor valido para {argument}.",
+	"args.invalid_duration":   "{value} no es un valor valido para {argument}, usa horas o minutos como 48h o 90m.",
+	"args.organizer_only":     "Solo el organizador del partido puede usar /{command}.",
+	"arg.numero de partido":   "numero de partido",
+	"arg.tamaño":              "tamaño",
+	"arg.fecha":               "fecha",
+	"arg.horario":             "horario",
+	"arg.direccion o cancha":  "direccion o cancha",
+	"arg.mantener":            "mantener",
+	"arg.nombre":              "nombre",
+	"arg.monto":               "monto",
+	"arg.opcion":              "opcion",
+	"arg.jugador":             "jugador",
+	"arg.minutos":             "minutos",
+	"arg.dia":                 "dia",
+	"arg.direccion":           "direccion",
+	"arg.direccion | notas":   "direccion | notas",
+	"arg.opcion 1 | opcion 2": "opcion 1 | opcion 2",
+	"arg.idioma":              "idioma",
+	"arg.plazo":               "plazo",
+	"help.title":              "Los comandos disponibles son:",
+	"help.yojuego":            "Únete a un partido",
+	"help.verpartido":         "Muestra la información de un partido",
+	"help.verpartidos":        "Muestra la información de todos los partidos",
+	"help.nuevopartido":       "Inicia un nuevo partido. Sin tamaño te pregunta paso a paso el tamaño, la fecha, el horario y la cancha",
+	"help.agregarfecha":       "Agrega la fecha a un partido",
+	"help.agregarhorario":     "Agrega un horario a un partido",
+	"help.agregardireccion":   "Agrega una dirección o una cancha guardada a un partido",
+	"help.cancelarpartido":    "Cancela un partido, solo la persona que lo creo puede cancelarlo",
+	"help.darsedebaja":        "Para bajarte de un partido. Con mantener tus invitados quedan a cargo del organizador",
+	"help.agregarinvitado":    "Para agregar a un invitado a un partido",
+	"help.bajarinvitado":      "Para dar de baja a un invitado de un partido, solo quien lo invito o el organizador pueden hacerlo",
+	"help.costo":              "Carga el costo de un partido para dividirlo entre los jugadores",
+	"help.pague":              "Marca que pagaste tu parte de un partido",
+	"help.deudas":             "Muestra lo que debe cada jugador",
+	"help.socios":             "Muestra los socios del grupo. Opciones: agregar, quitar (respondiendo a un mensaje del jugador) y ventana con los minutos de prioridad, solo para administradores",
+	"help.partidofijo":        "Crea un partido que se repite todas las semanas. Sin parametros muestra los partidos fijos",
+	"help.agregarcancha":      "Guarda una cancha. Respondiendo a una ubicacion se guarda el mapa",
+	"help.canchas":            "Muestra las canchas guardadas",
+	"help.borrarcancha":       "Borra una cancha guardada",
+	"help.votarfecha":         "Inicia una votacion para elegir la fecha de un partido. Cierra en 24 horas o en el plazo que indiques, como 48h",
+	"help.cerrarvotacion":     "Cierra la votacion de fecha antes de tiempo",
+	"help.ayuda":              "Muestra la lista de comandos disponibles",
+	"help.idioma":             "Muestra o cambia el idioma del bot en este chat, solo para administradores",
+
+	// Templates
+	"option.template_roster":   "partido",
+	"option.template_list":     "lista",
+	"option.template_creation": "creacion",
+	"option.template_reminder": "recordatorio",
+	"option.reset":             "restaurar",
+	"templates.list":           "Plantillas de mensajes. Usa /plantilla [nombre] para ver una, /plantilla [nombre] [plantilla] para cambiarla y /plantilla [nombre] restaurar para volver a la original:",
+	"templates.custom":         "(personalizada)",
+	"templates.fields":         "Las plantillas usan text/template y pueden mostrar: {fields}. Tambien tienen las funciones t, plural y add, y {{template \"detalles\" .Game}} muestra la fecha, el horario y la direccion. El recordatorio se envia al crear un partido fijo.",
+	"templates.unknown":        "No hay una plantilla llamada {template}. Usa /plantilla para ver las plantillas.",
+	"templates.current":        "Plantilla {template}:",
+	"templates.preview":        "Asi se ve con un partido de ejemplo:",
+	"templates.reset":          "Se restauro la plantilla {template}.",
+	"templates.invalid":        "La plantilla no es valida: {error}",
+	"templates.saved":          "Se guardo la plantilla {template}. Asi se ve con un partido de ejemplo:",
+	"args.admin_only":          "Solo los administradores del grupo pueden usar /{command}.",
+	"arg.plantilla":            "plantilla",
+	"help.plantilla":           "Muestra o cambia las plantillas de los mensajes del grupo, solo para administradores",
+
+	// Export
+	"arg.formato":           "formato",
+	"help.exportar":         "Te manda por privado un archivo con los partidos, jugadores, invitados y canchas del grupo, en json o csv, solo para administradores",
+	"export.caption":        "Partidos y canchas del grupo, en el formato de exportacion {version} de FulBot.",
+	"export.unknown_format": "No conozco el formato {format}, las opciones son: {options}",
+	"export.sent":           "@{name} te mande el archivo por privado.",
+	"export.private_failed": "@{name} no pude mandarte el archivo por privado, escribime algo por privado primero y volve a pedirlo.",
+
+	// My games
+	"help.mispartidos":         "Muestra tus proximos partidos de todos los grupos, con un boton para bajarte de cada uno. Se usa en privado con el bot",
+	"mygames.private_only":     "@{name} escribime /mispartidos por privado y te muestro tus partidos de todos los grupos.",
+	"mygames.none":             "No estas anotado en ningun partido pendiente, @{name}.",
+	"mygames.title":            "Tus proximos partidos, @{name}:",
+	"mygames.item":             "Partido {game} en {chat}, Jugadores: {players}/{max}",
+	"mygames.with_guests":      "Vas con tus invitados: {guests}",
+	"mygames.only_guests":      "No jugas, pero invitaste a: {guests}",
+	"mygames.leave":            "Bajarme del partido {game}",
+	"mygames.not_playing":      "Ya no estas en el partido {game}.",
+	"mygames.done":             "Te diste de baja del partido {game}.",
+	"mygames.left":             "@{name} se dio de baja del partido {game}.",
+	"mygames.left_with_guests": "@{name} se dio de baja del partido {game} junto con sus invitados: {guests}.",
+	"mygames.guests_left":      "@{name} dio de baja a sus invitados del partido {game}: {guests}.",
+}
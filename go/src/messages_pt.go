@@ -0,0 +1,270 @@
+package main
+
+var messagesPT = map[string]string{
+	// Members and priority window
+	"waitlist.closed":          "Terminou a prioridade dos sócios do jogo {game}.",
+	"waitlist.admitted":        "Entraram: {names}.",
+	"waitlist.left_out":        "Ficaram de fora porque o jogo lotou: {names}.",
+	"guest.invited_by":         "{guest} (convidado de {inviter})",
+	"option.add":               "adicionar",
+	"option.remove":            "remover",
+	"option.window":            "janela",
+	"members.empty":            "Ainda não há sócios neste grupo. Um administrador pode entrar com /socios adicionar, ou responder à mensagem de alguém com /socios adicionar para incluí-lo.",
+	"members.list":             "Sócios do grupo:",
+	"members.window.one":       "Os sócios têm prioridade durante {count} minuto depois de criar um jogo.",
+	"members.window.other":     "Os sócios têm prioridade durante {count} minutos depois de criar um jogo.",
+	"members.no_window":        "Não há janela de prioridade configurada. Use /socios janela [minutos] para ativá-la.",
+	"members.add_forbidden":    "Só os administradores do grupo podem adicionar sócios, @{name}.",
+	"members.already":          "{member} já é sócio.",
+	"members.added":            "{member} agora é sócio do grupo.",
+	"members.remove_forbidden": "Só os administradores do grupo podem remover outros sócios, @{name}.",
+	"members.not_member":       "{member} não é sócio.",
+	"members.removed":          "{member} não é mais sócio do grupo.",
+	"members.window_forbidden": "Só os administradores do grupo podem mudar a janela de prioridade, @{name}.",
+	"members.window_missing":   "@{name} você deve informar os minutos de prioridade! Exemplo: /socios janela [minutos]",
+	"members.window_disabled":  "A prioridade para sócios foi desativada.",
+	"members.window_set.one":   "Os sócios terão {count} minuto de prioridade nos próximos jogos.",
+	"members.window_set.other": "Os sócios terão {count} minutos de prioridade nos próximos jogos.",
+	"members.unknown_option":   "Não conheço a opção {option}, @{name}. As opções são: /socios, /socios adicionar, /socios remover e /socios janela [minutos]",
+
+	// Venues
+	"venues.address_missing": "@{name} você deve informar o endereço da quadra! Exemplo: /novaquadra [nome] [endereço] | [notas]",
+	"venues.updated":         "A quadra {venue} foi atualizada.",
+	"venues.saved":           "A quadra {venue} foi salva. Você pode usá-la com /endereco [número do jogo] {venue}",
+	"venues.empty":           "Não há quadras salvas. Você pode adicionar uma com /novaquadra [nome] [endereço]",
+	"venues.list":            "Quadras salvas:",
+	"venues.not_found":       "Não há uma quadra salva com o nome {venue}.",
+	"venues.removed":         "A quadra {venue} foi apagada.",
+
+	// Date polls
+	"poll.title":             "Votação de data para o jogo {game} (fecha em {date} às {time}):",
+	"poll.option.one":        "{option}: {count} voto",
+	"poll.option.other":      "{option}: {count} votos",
+	"poll.already_open":      "Já existe uma votação aberta para o jogo {game}. Você pode fechá-la com /fecharvotacao {game}",
+	"poll.options_missing":   "@{name} você deve informar entre 2 e 10 opções separadas por |. Exemplo: /votardata [número do jogo] terça 21:00 | quinta 20:00",
+	"poll.send_failed":       "Não foi possível criar a votação: {error}",
+	"poll.not_open":          "O jogo {game} não tem uma votação aberta.",
+	"poll.closed":            "A votação já está fechada.",
+	"poll.voted":             "Você votou {option}",
+	"poll.closed_footer":     "Votação fechada.",
+	"poll.winner.one":        "A votação do jogo {game} foi fechada. A data escolhida é {option} com {count} voto.",
+	"poll.winner.other":      "A votação do jogo {game} foi fechada. A data escolhida é {option} com {count} votos.",
+	"poll.deadline_too_long": "@{name} a votação pode durar no máximo {days} dias.",
+	"poll.no_votes":          "A votação do jogo {game} fechou sem votos, a data não muda.",
+
+	// Weekly games
+	"weekday.0":                      "domingo",
+	"weekday.1":                      "segunda",
+	"weekday.2":                      "terça",
+	"weekday.3":                      "quarta",
+	"weekday.4":                      "quinta",
+	"weekday.5":                      "sexta",
+	"weekday.6":                      "sábado",
+	"option.delete":                  "apagar",
+	"option.repeat":                  "repetir",
+	"option.ahead":                   "antecedencia",
+	"recurring.every":                "Toda {day} às {time}, jogo de {size}",
+	"recurring.at":                   "em {address}",
+	"recurring.days_ahead.one":       "É criado {count} dia antes",
+	"recurring.days_ahead.other":     "É criado {count} dias antes",
+	"recurring.keeps_regulars":       "e os jogadores da semana anterior são inscritos",
+	"recurring.created":              "Foi criado o jogo fixo de {date}: jogo {game}.",
+	"recurring.regulars_added.one":   "{count} jogador da semana passada já está inscrito.",
+	"recurring.regulars_added.other": "{count} jogadores da semana passada já estão inscritos.",
+	"recurring.join":                 "Você pode entrar com /eujogo {game}",
+	"recurring.empty":                "Não há jogos fixos. Você pode criar um com /jogofixo [dia] [horário] [tamanho] [endereço]. Exemplo: /jogofixo terça 21:00 5",
+	"recurring.list":                 "Jogos fixos:",
+	"recurring.number_missing":       "@{name} você deve informar o número do jogo fixo! Exemplo: /jogofixo {option} [número]",
+	"recurring.not_found":            "Não há um jogo fixo com esse número, @{name}.",
+	"recurring.forbidden":            "Só quem criou o jogo fixo pode modificá-lo.",
+	"recurring.deleted":              "O jogo fixo {recurring} foi apagado. Os jogos já criados não são cancelados.",
+	"recurring.repeat_on":            "Os jogadores de cada semana serão inscritos no jogo seguinte.",
+	"recurring.repeat_off":           "Os jogadores da semana anterior não serão mais inscritos automaticamente.",
+	"recurring.days_missing":         "@{name} você deve informar com quantos dias de antecedência o jogo é criado! Exemplo: /jogofixo antecedencia [número] [dias]",
+	"recurring.invalid_days":         "{days} não é uma quantidade de dias válida. Deve ser um número entre 1 e 6.",
+	"recurring.days_set.one":         "O jogo fixo {recurring} será criado {count} dia antes de ser jogado.",
+	"recurring.days_set.other":       "O jogo fixo {recurring} será criado {count} dias antes de ser jogado.",
+	"recurring.params_missing":       "Para criar um jogo fixo @{name}, você deve informar o dia, o horário e o tamanho. Exemplo: /jogofixo terça 21:00 5 [endereço]",
+	"recurring.invalid_weekday":      "{day} não é um dia da semana válido.",
+	"recurring.error":                "Erro ao criar o jogo fixo: {error}",
+	"recurring.saved":                "O jogo fixo foi criado:\n{recurring}\nVocê pode mudar a antecedência com /jogofixo antecedencia {id} [dias] e inscrever os da semana anterior com /jogofixo repetir {id}",
+	"time.invalid":                   "{time} não é um horário válido.",
+
+	// Game creation wizard
+	"wizard.size":           "@{name}, quantos jogadores por time?",
+	"wizard.date":           "@{name}, que dia é o jogo?",
+	"wizard.schedule":       "@{name}, a que horas?",
+	"wizard.venue":          "@{name}, onde é o jogo? Escolha uma quadra salva ou escreva o endereço.",
+	"wizard.hint":           "Você pode tocar em uma opção ou responder a esta mensagem.",
+	"wizard.back":           "Voltar",
+	"wizard.skip":           "Sem endereço",
+	"wizard.cancel":         "Cancelar",
+	"wizard.not_understood": "Não entendi a resposta.",
+	"wizard.join":           "Eu jogo",
+	"wizard.not_yours":      "Só quem está criando o jogo pode escolher.",
+	"wizard.cancelled":      "A criação do jogo foi cancelada, @{name}.",
+	"wizard.unavailable":    "O jogo não está mais disponível.",
+	"wizard.expired":        "A criação do jogo foi cancelada por inatividade, @{name}. Você pode começar de novo com /novojogo",
+
+	// Games
+	"game.title":          "Jogo {game}:",
+	"game.date":           "Data: {date}",
+	"game.schedule":       "Horário: {schedule}",
+	"game.address":        "Endereço: {address}",
+	"game.players":        "Jogadores:",
+	"game.total":          "Total de jogadores: {count}/{max}",
+	"game.waitlist":       "Lista de espera (prioridade para sócios até as {time}):",
+	"game.cost":           "Custo: {cost} ({share} por jogador)",
+	"game.all_paid":       "Todos já pagaram.",
+	"game.unpaid":         "Falta pagar:",
+	"game.cancelled":      "O jogo foi cancelado por @{name}.",
+	"games.none":          "Não há jogos pendentes, @{name}. Você pode iniciar um novo com /novojogo",
+	"games.title":         "Próximos jogos:",
+	"games.item":          "Jogo {game}, Jogadores: {players}/{max}",
+	"games.total.one":     "Total de jogos: {count}.",
+	"games.total.other":   "Total de jogos: {count}.",
+	"games.more":          "Você pode usar /verjogo [número do jogo] para mais informações.",
+	"newgame.error":       "Erro ao criar o jogo: {error}",
+	"newgame.created":     "Foi iniciado um novo jogo de {size}. Você pode entrar no jogo com o comando /eujogo {game}",
+	"newgame.priority":    "Os sócios têm prioridade até as {time}, o resto fica na lista de espera até lá.",
+	"error.invalid_size":  "O tamanho informado não é válido. Deve ser um número entre 1 e {max}.",
+	"address.added":       "O endereço foi adicionado ao jogo {game}.",
+	"address.venue_added": "A quadra {venue} foi adicionada ao jogo {game}.",
+	"schedule.added":      "O horário foi adicionado ao jogo {game}.",
+	"date.added":          "A data foi adicionada ao jogo {game}.",
+
+	// Joining and leaving
+	"join.already_waiting": "Você já está na lista de espera @{name}.",
+	"join.waitlisted":      "@{name} os sócios têm prioridade até as {time}. Você fica na lista de espera e entra se houver vaga quando terminar.",
+	"join.joined":          "Olá @{name}! Você entrou no jogo. Boa sorte!",
+	"join.already":         "Você já está no jogo @{name}. Bora jogar!",
+	"join.full":            "O jogo está lotado @{name}, você não pode entrar",
+	"option.keep":          "manter",
+	"leave.waitlist":       "Você saiu da lista de espera, @{name}.",
+	"leave.not_playing":    "Não é possível sair, @{name}. Você não está no jogo.",
+	"leave.done":           "Você saiu do jogo, @{name}.",
+	"leave.guests_kept":    "Seus convidados ({guests}) ficam a cargo do organizador.",
+	"leave.guests_removed": "Seus convidados também saíram: {guests}.",
+
+	// Guests
+	"guests.not_found":        "Não é possível remover {guest}. Não está no jogo.",
+	"guests.remove_forbidden": "Só quem convidou {guest} ou o organizador do jogo podem removê-lo.",
+	"guests.removed":          "@{name} você removeu {guest}.",
+	"guests.duplicate":        "Já existe um convidado chamado {guest} no jogo, @{name}. Tente outro nome, por exemplo adicionando o sobrenome.",
+	"guests.limit.one":        "@{name} você já convidou {count} jogador para este jogo, não pode convidar mais.",
+	"guests.limit.other":      "@{name} você já convidou {count} jogadores para este jogo, não pode convidar mais.",
+	"guests.waitlisted":       "@{name} os sócios têm prioridade até as {time}. {guest} fica na lista de espera e entra se houver vaga quando terminar.",
+	"guests.added":            "@{name} você convidou {guest} para o jogo.",
+	"guests.full":             "O jogo está lotado @{name}, você não pode convidar {guest}",
+
+	// Payments
+	"cost.set":    "Foi definido um custo de {cost} para o jogo {game}.",
+	"cost.share":  "Por enquanto são {share} por jogador.",
+	"pay.no_cost": "O jogo {game} ainda não tem um custo definido, @{name}.",
+	"pay.nothing": "Você não tem nada a pagar neste jogo, @{name}.",
+	"pay.already": "Você já tinha marcado seu pagamento, @{name}.",
+	"pay.done":    "Obrigado @{name}, seu pagamento de {amount} foi registrado.",
+	"debts.none":  "Não há dívidas pendentes.",
+	"debts.title": "Dívidas pendentes:",
+	"debts.total": "Total devido: {amount}.",
+
+	// Languages
+	"language.current": "O idioma deste chat é {language}. Você pode mudá-lo com /idioma [idioma], as opções são: {options}",
+	"language.unknown": "Não conheço o idioma {language}. Use /idioma para ver as opções.",
+	"language.set":     "Pronto, agora falo {language} neste chat.",
+
+	// Commands
+	"command.unknown":         "Comando desconhecido. Use /ajuda para ver a lista de comandos disponíveis.",
+	"command.error":           "Desculpe, ocorreu um erro ao processar o comando.",
+	"player.unknown":          "Jogador {id}",
+	"args.user_missing":       "@{name}, para usar /{command} você deve responder à mensagem do {argument}. Exemplo: {usage}",
+	"args.missing":            "@{name}, para usar /{command} você deve informar {argument}. Exemplo: {usage}",
+	"args.invalid_game":       "{value} não é um número de jogo válido.",
+	"args.game_not_found":     "Não há um jogo pendente com esse número, @{name}. Você pode iniciar um novo com /novojogo",
+	"args.invalid_number":     "{value} não é um valor válido para {argument}.",
+	"args.invalid_duration":   "{value} não é um valor válido para {argument}, use horas ou minutos como 48h ou 90m.",
+	"args.organizer_only":     "Só o organizador do jogo pode usar /{command}.",
+	"arg.numero de partido":   "número do jogo",
+	"arg.tamaño":              "tamanho",
+	"arg.fecha":               "data",
+	"arg.horario":             "horário",
+	"arg.direccion o cancha":  "endereço ou quadra",
+	"arg.mantener":            "manter",
+	"arg.nombre":              "nome",
+	"arg.monto":               "valor",
+	"arg.opcion":              "opção",
+	"arg.jugador":             "jogador",
+	"arg.minutos":             "minutos",
+	"arg.dia":                 "dia",
+	"arg.direccion":           "endereço",
+	"arg.direccion | notas":   "endereço | notas",
+	"arg.opcion 1 | opcion 2": "opção 1 | opção 2",
+	"arg.idioma":              "idioma",
+	"arg.plazo":               "prazo",
+	"help.title":              "Os comandos disponíveis são:",
+	"help.yojuego":            "Entre em um jogo",
+	"help.verpartido":         "Mostra as informações de um jogo",
+	"help.verpartidos":        "Mostra as informações de todos os jogos",
+	"help.nuevopartido":       "Inicia um novo jogo. Sem tamanho pergunta passo a passo o tamanho, a data, o horário e a quadra",
+	"help.agregarfecha":       "Define a data de um jogo",
+	"help.agregarhorario":     "Define o horário de um jogo",
+	"help.agregardireccion":   "Define um endereço ou uma quadra salva para um jogo",
+	"help.cancelarpartido":    "Cancela um jogo, só quem o criou pode cancelá-lo",
+	"help.darsedebaja":        "Para sair de um jogo. Com manter seus convidados ficam a cargo do organizador",
+	"help.agregarinvitado":    "Para convidar alguém para um jogo",
+	"help.bajarinvitado":      "Para remover um convidado de um jogo, só quem o convidou ou o organizador podem fazê-lo",
+	"help.costo":              "Define o custo de um jogo para dividi-lo entre os jogadores",
+	"help.pague":              "Marca que você pagou sua parte de um jogo",
+	"help.deudas":             "Mostra quanto deve cada jogador",
+	"help.socios":             "Mostra os sócios do grupo. Opções: adicionar, remover (respondendo a uma mensagem do jogador) e janela com os minutos de prioridade, só para administradores",
+	"help.partidofijo":        "Cria um jogo que se repete toda semana. Sem parâmetros mostra os jogos fixos",
+	"help.agregarcancha":      "Salva uma quadra. Respondendo a uma localização salva o mapa",
+	"help.canchas":            "Mostra as quadras salvas",
+	"help.borrarcancha":       "Apaga uma quadra salva",
+	"help.votarfecha":         "Inicia uma votação para escolher a data de um jogo. Fecha em 24 horas ou no prazo que você indicar, como 48h",
+	"help.cerrarvotacion":     "Fecha a votação de data antes do prazo",
+	"help.ayuda":              "Mostra a lista de comandos disponíveis",
+	"help.idioma":             "Mostra ou muda o idioma do bot neste chat, só para administradores",
+
+	// Templates
+	"option.template_roster":   "escalacao",
+	"option.template_list":     "lista",
+	"option.template_creation": "criacao",
+	"option.template_reminder": "lembrete",
+	"option.reset":             "restaurar",
+	"templates.list":           "Modelos de mensagens. Use /modelo [nome] para ver um, /modelo [nome] [modelo] para mudá-lo e /modelo [nome] restaurar para voltar ao original:",
+	"templates.custom":         "(personalizado)",
+	"templates.fields":         "Os modelos usam text/template e podem mostrar: {fields}. Também têm as funções t, plural e add, e {{template \"detalles\" .Game}} mostra a data, o horário e o endereço. O lembrete é enviado ao criar um jogo fixo.",
+	"templates.unknown":        "Não há um modelo chamado {template}. Use /modelo para ver os modelos.",
+	"templates.current":        "Modelo {template}:",
+	"templates.preview":        "Assim fica com um jogo de exemplo:",
+	"templates.reset":          "O modelo {template} foi restaurado.",
+	"templates.invalid":        "O modelo não é válido: {error}",
+	"templates.saved":          "O modelo {template} foi salvo. Assim fica com um jogo de exemplo:",
+	"args.admin_only":          "Só os administradores do grupo podem usar /{command}.",
+	"arg.plantilla":            "modelo",
+	"help.plantilla":           "Mostra ou muda os modelos das mensagens do grupo, só para administradores",
+
+	// Export
+	"arg.formato":           "formato",
+	"help.exportar":         "Te envia no privado um arquivo com os jogos, jogadores, convidados e quadras do grupo, em json ou csv, só para administradores",
+	"export.caption":        "Jogos e quadras do grupo, na versão {version} do formato de exportação do FulBot.",
+	"export.unknown_format": "Não conheço o formato {format}, as opções são: {options}",
+	"export.sent":           "@{name} te mandei o arquivo no privado.",
+	"export.private_failed": "@{name} não consegui te mandar o arquivo no privado, me mande uma mensagem no privado primeiro e peça de novo.",
+
+	// My games
+	"help.mispartidos":         "Mostra seus próximos jogos em todos os grupos, com um botão para sair de cada um. Use no chat privado com o bot",
+	"mygames.private_only":     "@{name} me mande /meusjogos no privado e eu mostro seus jogos de todos os grupos.",
+	"mygames.none":             "Você não está inscrito em nenhum jogo pendente, @{name}.",
+	"mygames.title":            "Seus próximos jogos, @{name}:",
+	"mygames.item":             "Jogo {game} em {chat}, Jogadores: {players}/{max}",
+	"mygames.with_guests":      "Você vai com seus convidados: {guests}",
+	"mygames.only_guests":      "Você não joga, mas convidou: {guests}",
+	"mygames.leave":            "Sair do jogo {game}",
+	"mygames.not_playing":      "Você não está mais no jogo {game}.",
+	"mygames.done":             "Você saiu do jogo {game}.",
+	"mygames.left":             "@{name} saiu do jogo {game}.",
+	"mygames.left_with_guests": "@{name} saiu do jogo {game} junto com seus convidados: {guests}.",
+	"mygames.guests_left":      "@{name} tirou seus convidados do jogo {game}: {guests}.",
+}
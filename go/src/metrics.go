@@ -0,0 +1,325 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// The bot serves Prometheus metrics on /metrics, in the text exposition format, along with /healthz and /readyz for
+// whatever keeps it running. The counters are kept here and the gauges are computed from the games and outboxes on
+// every scrape.
+
+// counterVec is a Prometheus counter with labels.
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mutex  sync.Mutex
+	values map[string]float64
+}
+
+func newCounterVec(name string, help string, labels ...string) *counterVec {
+	return &counterVec{name: name, help: help, labels: labels, values: make(map[string]float64)}
+}
+
+// inc adds one to the counter with the given label values, in the order of the label names.
+func (counter *counterVec) inc(values ...string) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	counter.values[strings.Join(values, "\xff")]++
+}
+
+func (counter *counterVec) get(values ...string) float64 {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	return counter.values[strings.Join(values, "\xff")]
+}
+
+func (counter *counterVec) write(w io.Writer) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	writeHeader(w, counter.name, counter.help, "counter")
+	keys := make([]string, 0, len(counter.values))
+	for key := range counter.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s%s %s\n", counter.name, formatLabels(counter.labels, strings.Split(key, "\xff")), formatValue(counter.values[key]))
+	}
+}
+
+// histogram is a Prometheus histogram without labels.
+type histogram struct {
+	name    string
+	help    string
+	buckets []float64
+
+	mutex  sync.Mutex
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogram(name string, help string, buckets ...float64) *histogram {
+	return &histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (histogram *histogram) observe(value float64) {
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+	for i, bound := range histogram.buckets {
+		if value <= bound {
+			histogram.counts[i]++
+		}
+	}
+	histogram.sum += value
+	histogram.count++
+}
+
+func (histogram *histogram) write(w io.Writer) {
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+	writeHeader(w, histogram.name, histogram.help, "histogram")
+	for i, bound := range histogram.buckets {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", histogram.name, formatValue(bound), histogram.counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", histogram.name, histogram.count)
+	fmt.Fprintf(w, "%s_sum %s\n", histogram.name, formatValue(histogram.sum))
+	fmt.Fprintf(w, "%s_count %d\n", histogram.name, histogram.count)
+}
+
+func writeHeader(w io.Writer, name string, help string, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeGauge(w io.Writer, name string, help string, value float64) {
+	writeHeader(w, name, help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", name, formatValue(value))
+}
+
+func formatLabels(names []string, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + strconv.Quote(values[i])
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var updatesReceived = newCounterVec("fulbot_updates_received_total", "Updates received from Telegram, by type.", "type")
+var commandsHandled = newCounterVec("fulbot_commands_total", "Commands handled, by command and outcome.", "command", "outcome")
+var handlerDuration = newHistogram("fulbot_handler_duration_seconds", "Time taken to handle an update.",
+	0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10)
+var handlerPanics = newCounterVec("fulbot_handler_panics_total", "Handlers that panicked.")
+var telegramErrors = newCounterVec("fulbot_telegram_api_errors_total", "Failed Bot API calls, by method and HTTP status or network.", "method", "code")
+
+// Command outcomes, for fulbot_commands_total.
+const (
+	outcomeOK               = "ok"
+	outcomeInvalidArguments = "invalid_arguments"
+	outcomeUnknown          = "unknown"
+	outcomePanic            = "panic"
+)
+
+func updateType(hasMessage bool, hasCallback bool) string {
+	switch {
+	case hasMessage:
+		return "message"
+	case hasCallback:
+		return "callback_query"
+	}
+	return "other"
+}
+
+// outboxQueueDepth counts the messages waiting in every outbox.
+func outboxQueueDepth() int {
+	outboxesMutex.Lock()
+	defer outboxesMutex.Unlock()
+	depth := 0
+	for _, box := range outboxes {
+		depth += box.pending
+	}
+	return depth
+}
+
+// activeGameStats counts the pending games and the players and guests signed up to them.
+func activeGameStats() (active int, enrolled int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for _, game := range games {
+		if game.Active {
+			active++
+			enrolled += len(game.Players) + len(game.Guests)
+		}
+	}
+	return active, enrolled
+}
+
+// writeMetrics writes every metric in the Prometheus text format.
+func writeMetrics(w io.Writer) {
+	updatesReceived.write(w)
+	commandsHandled.write(w)
+	handlerDuration.write(w)
+	handlerPanics.write(w)
+	telegramErrors.write(w)
+
+	writeHeader(w, "fulbot_messages_total", "Outgoing messages, by what happened to them.", "counter")
+	for _, result := range []struct {
+		name  string
+		value *int64
+	}{
+		{"sent", &deliveryMetrics.Sent},
+		{"retried", &deliveryMetrics.Retried},
+		{"rate_limited", &deliveryMetrics.RateLimited},
+		{"failed", &deliveryMetrics.Failed},
+	} {
+		fmt.Fprintf(w, "fulbot_messages_total{result=%q} %d\n", result.name, atomic.LoadInt64(result.value))
+	}
+
+	writeGauge(w, "fulbot_send_queue_depth", "Messages waiting in the outboxes.", float64(outboxQueueDepth()))
+	active, enrolled := activeGameStats()
+	writeGauge(w, "fulbot_active_games", "Pending games.", float64(active))
+	writeGauge(w, "fulbot_players_enrolled", "Players and guests signed up to pending games.", float64(enrolled))
+	up := 0.0
+	if telegramHealth.check() == nil {
+		up = 1
+	}
+	writeGauge(w, "fulbot_telegram_up", "Whether the last Bot API call reached Telegram.", up)
+}
+
+// telegramUnhealthyAfter is how long Telegram can be unreachable before /healthz fails and the bot should restart.
+var telegramUnhealthyAfter = 5 * time.Minute
+
+// connectivity follows whether the Bot API calls reach Telegram.
+type connectivity struct {
+	mutex       sync.Mutex
+	connected   bool
+	lastSuccess time.Time
+	lastError   error
+}
+
+var telegramHealth = &connectivity{}
+
+// record notes the result of a Bot API call. Errors Telegram answers with, like a message that did not change, still
+// mean it is reachable.
+func (health *connectivity) record(err error) {
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+	health.lastError = err
+	if err == nil {
+		health.connected = true
+		health.lastSuccess = time.Now()
+	}
+}
+
+// check tells if the last Bot API call reached Telegram.
+func (health *connectivity) check() error {
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+	if !health.connected {
+		return fmt.Errorf("not connected yet")
+	}
+	return health.lastError
+}
+
+// stuck tells if Telegram has been unreachable for too long.
+func (health *connectivity) stuck() error {
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+	if health.connected && health.lastError != nil && time.Since(health.lastSuccess) > telegramUnhealthyAfter {
+		return fmt.Errorf("unreachable since %s: %v", health.lastSuccess.Format(time.RFC3339), health.lastError)
+	}
+	return nil
+}
+
+// checkStorage tells if the games can be read and saved. The memory backend always can.
+func checkStorage() error {
+	return nil
+}
+
+// metricsTransport counts the Bot API calls that fail and follows whether Telegram is reachable.
+type metricsTransport struct {
+	next http.RoundTripper
+}
+
+func (transport metricsTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	method := request.URL.Path[strings.LastIndex(request.URL.Path, "/")+1:]
+	response, err := transport.next.RoundTrip(request)
+	switch {
+	case err != nil:
+		telegramErrors.inc(method, "network")
+		telegramHealth.record(err)
+	case response.StatusCode >= http.StatusBadRequest:
+		telegramErrors.inc(method, strconv.Itoa(response.StatusCode))
+		if response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusUnauthorized {
+			telegramHealth.record(fmt.Errorf("%s answered %s", method, response.Status))
+		} else {
+			telegramHealth.record(nil)
+		}
+	default:
+		telegramHealth.record(nil)
+	}
+	return response, err
+}
+
+// metricsHandler serves /metrics, /healthz and /readyz.
+func metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, request *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		writeMetrics(w)
+	})
+	// healthz fails when restarting could help: the storage is broken or Telegram has been unreachable for long.
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, request *http.Request) {
+		writeChecks(w, map[string]error{"storage": checkStorage(), "telegram": telegramHealth.stuck()})
+	})
+	// readyz fails while the bot cannot handle updates right now.
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, request *http.Request) {
+		writeChecks(w, map[string]error{"storage": checkStorage(), "telegram": telegramHealth.check()})
+	})
+	return mux
+}
+
+// writeChecks answers with a line per check, and 503 if any failed.
+func writeChecks(w http.ResponseWriter, checks map[string]error) {
+	names := make([]string, 0, len(checks))
+	status := http.StatusOK
+	for name, err := range checks {
+		names = append(names, name)
+		if err != nil {
+			status = http.StatusServiceUnavailable
+		}
+	}
+	sort.Strings(names)
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	for _, name := range names {
+		if err := checks[name]; err != nil {
+			fmt.Fprintf(w, "%s: %v\n", name, err)
+		} else {
+			fmt.Fprintf(w, "%s: ok\n", name)
+		}
+	}
+}
+
+// serveMetrics serves the metrics and health checks until the bot stops.
+func serveMetrics(listen string) {
+	slog.Info("serving metrics and health checks", "listen", listen)
+	checkForFatalError("error serving metrics", http.ListenAndServe(listen, metricsHandler()))
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, path string) (int, string) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	metricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	return recorder.Code, recorder.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	ok := commandsHandled.get("yojuego", outcomeOK)
+	invalid := commandsHandled.get("yojuego", outcomeInvalidArguments)
+	updates := updatesReceived.get("message")
+
+	chat := newTestGroup(t)
+	id := strconv.Itoa(chat.newGame(ana, "2"))
+	chat.command(ana, "/yojuego "+id, "Te has unido al partido.")
+	chat.command(ana, "/yojuego abc", "abc no es un numero de partido valido.")
+	handlers.Wait()
+
+	if got := commandsHandled.get("yojuego", outcomeOK) - ok; got != 1 {
+		t.Errorf("expected 1 successful /yojuego, got %v", got)
+	}
+	if got := commandsHandled.get("yojuego", outcomeInvalidArguments) - invalid; got != 1 {
+		t.Errorf("expected 1 /yojuego with invalid arguments, got %v", got)
+	}
+	if got := updatesReceived.get("message") - updates; got != 3 {
+		t.Errorf("expected 3 messages received, got %v", got)
+	}
+
+	code, body := scrape(t, "/metrics")
+	if code != http.StatusOK {
+		t.Fatalf("expected the metrics, got %d", code)
+	}
+	assertContains(t, body,
+		"# TYPE fulbot_commands_total counter\n",
+		`fulbot_commands_total{command="yojuego",outcome="ok"} `,
+		"# TYPE fulbot_handler_duration_seconds histogram\n",
+		`fulbot_handler_duration_seconds_bucket{le="+Inf"} `,
+		`fulbot_messages_total{result="sent"} `,
+		"\nfulbot_send_queue_depth ",
+		"\nfulbot_active_games ",
+		"\nfulbot_players_enrolled ",
+	)
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		if !strings.HasPrefix(line, "#") && len(strings.Fields(line)) != 2 {
+			t.Errorf("expected a metric and a value, got %q", line)
+		}
+	}
+}
+
+type failingTransport struct {
+	status int
+	err    error
+}
+
+func (transport failingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if transport.err != nil {
+		return nil, transport.err
+	}
+	return &http.Response{StatusCode: transport.status, Status: http.StatusText(transport.status), Body: http.NoBody}, nil
+}
+
+func TestHealthChecks(t *testing.T) {
+	previous := telegramHealth
+	telegramHealth = &connectivity{}
+	defer func() { telegramHealth = previous }()
+
+	call := func(transport failingTransport) {
+		request := httptest.NewRequest(http.MethodPost, "https://api.telegram.org/bot"+fakeToken+"/getUpdates", nil)
+		metricsTransport{next: transport}.RoundTrip(request)
+	}
+
+	if code, body := scrape(t, "/readyz"); code != http.StatusServiceUnavailable || !strings.Contains(body, "telegram: not connected yet") {
+		t.Errorf("expected not to be ready before connecting, got %d %q", code, body)
+	}
+	call(failingTransport{status: http.StatusOK})
+	if code, body := scrape(t, "/readyz"); code != http.StatusOK || body != "storage: ok\ntelegram: ok\n" {
+		t.Errorf("expected to be ready, got %d %q", code, body)
+	}
+
+	// Telegram rejecting a call still means it is reachable.
+	call(failingTransport{status: http.StatusBadRequest})
+	if code, _ := scrape(t, "/readyz"); code != http.StatusOK {
+		t.Errorf("expected a 400 to keep the bot ready, got %d", code)
+	}
+
+	networkErrors := telegramErrors.get("getUpdates", "network")
+	call(failingTransport{err: errors.New("connection refused")})
+	if code, _ := scrape(t, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("expected not to be ready while Telegram is unreachable, got %d", code)
+	}
+	if code, _ := scrape(t, "/healthz"); code != http.StatusOK {
+		t.Errorf("expected to stay healthy for a while, got %d", code)
+	}
+	if got := telegramErrors.get("getUpdates", "network") - networkErrors; got != 1 {
+		t.Errorf("expected the network error to be counted, got %v", got)
+	}
+
+	telegramHealth.lastSuccess = time.Now().Add(-telegramUnhealthyAfter - time.Minute)
+	if code, body := scrape(t, "/healthz"); code != http.StatusServiceUnavailable || !strings.Contains(body, "connection refused") {
+		t.Errorf("expected to be unhealthy after a long outage, got %d %q", code, body)
+	}
+}
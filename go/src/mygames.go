@@ -0,0 +1,133 @@
+package main
+
+import (
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// In a private chat with the bot, /mispartidos lists the pending games of every group the user plays in or invited
+// guests to, with a button to leave each one without going back to the group.
+
+// userGames returns the pending games a user plays in or invited guests to, sorted by number.
+func userGames(userID int) []Game {
+	mutex.Lock()
+	mine := make([]Game, 0)
+	for _, game := range games {
+		if game.Active && (contains(game.Players, userID) || len(game.guestsInvitedBy(userID)) > 0) {
+			mine = append(mine, game)
+		}
+	}
+	mutex.Unlock()
+
+	sort.Slice(mine, func(i, j int) bool { return mine[i].Id < mine[j].Id })
+	return mine
+}
+
+// userGamesText describes the games of a user with their dates, venues and guests. titles are the names of the
+// groups, a game from a group without a known title is listed without it.
+func userGamesText(language string, user *tgbotapi.User, mine []Game, titles map[int64]string) string {
+	if len(mine) == 0 {
+		return tr(language, "mygames.none", "name", user.FirstName)
+	}
+
+	var text strings.Builder
+	text.WriteString(tr(language, "mygames.title", "name", user.FirstName) + "\n")
+	for _, game := range mine {
+		view := gameView(language, game, nil)
+		text.WriteString("\n" + unicodeBulletPoint + " ")
+		if title, known := titles[game.ChatID]; known {
+			text.WriteString(tr(language, "mygames.item", "game", game.Id, "chat", title, "players", view.Count, "max", view.MaxPlayers))
+		} else {
+			text.WriteString(tr(language, "games.item", "game", game.Id, "players", view.Count, "max", view.MaxPlayers))
+		}
+		details, err := executeTemplate(language, `{{template "detalles" .Game}}`, TemplateData{Game: view})
+		if err != nil && err != errEmptyTemplate {
+			slog.Error("error rendering the details of a game", "game_id", game.Id, "error", err)
+		}
+		text.WriteString(details)
+		if guests := game.guestsInvitedBy(user.ID); len(guests) > 0 {
+			key := "mygames.with_guests"
+			if !contains(game.Players, user.ID) {
+				key = "mygames.only_guests"
+			}
+			text.WriteString("\n    - " + tr(language, key, "guests", joinGuestNames(guests)))
+		}
+		text.WriteString("\n")
+	}
+	return text.String()
+}
+
+// userGamesKeyboard has a button to leave each game.
+func userGamesKeyboard(language string, mine []Game) *tgbotapi.InlineKeyboardMarkup {
+	if len(mine) == 0 {
+		return nil
+	}
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(mine))
+	for _, game := range mine {
+		label := emojiThumbsDown + " " + tr(language, "mygames.leave", "game", game.Id)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(label, "baja:"+strconv.Itoa(game.Id))))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+func handleMisPartidosCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	if !message.Chat.IsPrivate() {
+		respondToMessage(message, tr(args.Language, "mygames.private_only", "name", message.From.FirstName))
+		return
+	}
+
+	mine := userGames(message.From.ID)
+	msg := tgbotapi.NewMessage(message.Chat.ID, userGamesText(args.Language, message.From, mine, knownChatTitles()))
+	if keyboard := userGamesKeyboard(args.Language, mine); keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	sendText(msg)
+}
+
+// handleLeaveButtonCallback takes the user out of a game from the /mispartidos list, tells the group and refreshes
+// the list.
+func handleLeaveButtonCallback(bot TelegramClient, query *tgbotapi.CallbackQuery) {
+	language := defaultLanguage
+	if query.Message != nil {
+		language = chatLanguage(query.Message.Chat.ID)
+	}
+	gameId, _ := strconv.Atoi(strings.TrimPrefix(query.Data, "baja:"))
+	game, exists := getGame(gameId)
+	userID := query.From.ID
+	switch {
+	case !exists || !game.Active:
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, tr(language, "wizard.unavailable")))
+	case !contains(game.Players, userID) && len(game.guestsInvitedBy(userID)) == 0:
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, tr(language, "mygames.not_playing", "game", game.Id)))
+	default:
+		playing := contains(game.Players, userID)
+		guests := removePlayer(&game, userID, false)
+		updateGame(game.Id, game)
+		gameLogger(game).Info("player left from the private chat", "user_id", userID)
+
+		groupLanguage := chatLanguage(game.ChatID)
+		var notice string
+		switch {
+		case !playing:
+			notice = tr(groupLanguage, "mygames.guests_left", "name", query.From.FirstName, "game", game.Id, "guests", joinGuestNames(guests))
+		case len(guests) > 0:
+			notice = tr(groupLanguage, "mygames.left_with_guests", "name", query.From.FirstName, "game", game.Id, "guests", joinGuestNames(guests))
+		default:
+			notice = tr(groupLanguage, "mygames.left", "name", query.From.FirstName, "game", game.Id)
+		}
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, tr(language, "mygames.done", "game", game.Id)))
+		sendMessage(game.ChatID, notice)
+	}
+
+	if query.Message != nil {
+		mine := userGames(userID)
+		edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, userGamesText(language, query.From, mine, knownChatTitles()))
+		edit.ReplyMarkup = userGamesKeyboard(language, mine)
+		editText(edit)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestMisPartidos(t *testing.T) {
+	// Dario plays in no other test, so the list only has the games of this one.
+	dario := tgbotapi.User{ID: 104, FirstName: "Dario"}
+	martes := newTestGroup(t)
+	jueves := newTestGroup(t)
+	jueves.chat.Title = "Jueves"
+	private := newTestPrivateChat(t, dario)
+
+	private.command(dario, "/mispartidos", "No estas anotado en ningun partido pendiente, @Dario.")
+
+	first := martes.newGame(ana, "5")
+	martes.command(dario, "/yojuego "+strconv.Itoa(first), "¡Hola @Dario!")
+	martes.command(dario, "/agregarinvitado "+strconv.Itoa(first)+" Tito", "Tito")
+	second := jueves.newGame(bruno, "5")
+	jueves.command(dario, "/agregarinvitado "+strconv.Itoa(second)+" Nico", "Nico")
+	jueves.command(bruno, "/agregardireccion "+strconv.Itoa(second)+" Av. Siempre Viva 742", "Se ha agregado la dirección")
+	other := jueves.newGame(bruno, "5")
+
+	martes.command(dario, "/mispartidos", "escribime /mispartidos por privado")
+
+	private.send(dario, "/mispartidos")
+	list := private.expect()
+	assertContains(t, list.text(), "Tus proximos partidos, @Dario:",
+		"Partido "+strconv.Itoa(first)+" en Futbol, Jugadores: 2/10", "Vas con tus invitados: Tito",
+		"Partido "+strconv.Itoa(second)+" en Jueves, Jugadores: 1/10", "Av. Siempre Viva 742", "No jugas, pero invitaste a: Nico")
+	assertNotContains(t, list.text(), "Partido "+strconv.Itoa(other)+" ")
+	assertContains(t, list.Params.Get("reply_markup"), "baja:"+strconv.Itoa(first), "baja:"+strconv.Itoa(second))
+
+	private.press(dario, list, "baja:"+strconv.Itoa(first))
+	if answer := private.expect(); answer.text() != "Te diste de baja del partido "+strconv.Itoa(first)+"." {
+		t.Errorf("unexpected callback answer %q", answer.text())
+	}
+	assertContains(t, martes.expect().text(), "@Dario se dio de baja del partido "+strconv.Itoa(first)+" junto con sus invitados: Tito.")
+	edited := private.expect()
+	if edited.Method != "editMessageText" {
+		t.Fatalf("expected the list to be refreshed, got %s", edited.Method)
+	}
+	assertNotContains(t, edited.text(), "Partido "+strconv.Itoa(first)+" ")
+	assertContains(t, edited.text(), "Partido "+strconv.Itoa(second)+" en Jueves")
+	if game, _ := getGame(first); contains(game.Players, dario.ID) || len(game.Guests) != 0 {
+		t.Errorf("expected Dario and Tito out of the game, got %+v", game)
+	}
+
+	private.press(dario, list, "baja:"+strconv.Itoa(first))
+	if answer := private.expect(); answer.text() != "Ya no estas en el partido "+strconv.Itoa(first)+"." {
+		t.Errorf("unexpected callback answer %q", answer.text())
+	}
+	private.expect()
+
+	private.press(dario, edited, "baja:"+strconv.Itoa(second))
+	private.expect()
+	assertContains(t, jueves.expect().text(), "@Dario dio de baja a sus invitados del partido "+strconv.Itoa(second)+": Nico.")
+	assertContains(t, private.expect().text(), "No estas anotado en ningun partido pendiente, @Dario.")
+}
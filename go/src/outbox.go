@@ -0,0 +1,196 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Everything the bot posts to a chat goes through the outbox of that chat, which sends one message at a time within
+// Telegram's limits (about one message per second in a private chat, twenty per minute in a group and thirty per
+// second overall, see RateLimitConfig), waits when Telegram answers 429 with retry_after, and retries transient
+// failures with backoff.
+
+var maxDeliveryAttempts = 5
+var initialDeliveryBackoff = time.Second
+var outboxIdleTimeout = time.Minute
+
+// transientErrors are the Telegram error descriptions worth trying again.
+var transientErrors = []string{"Too Many Requests", "Internal Server Error", "Bad Gateway", "Gateway Timeout", "Service Unavailable"}
+
+// DeliveryMetrics counts what happened to outgoing messages. Fields are updated atomically.
+type DeliveryMetrics struct {
+	Sent        int64
+	Retried     int64
+	RateLimited int64
+	Failed      int64
+}
+
+var deliveryMetrics DeliveryMetrics
+
+type outgoing struct {
+	chattable tgbotapi.Chattable
+	result    chan delivery
+}
+
+type delivery struct {
+	message tgbotapi.Message
+	err     error
+}
+
+// rateLimiter hands out send slots at least an interval apart. The interval is given on every wait, so changes to the
+// rate limits apply right away.
+type rateLimiter struct {
+	mutex sync.Mutex
+	next  time.Time
+}
+
+func (limiter *rateLimiter) wait(interval time.Duration) {
+	limiter.mutex.Lock()
+	slot := limiter.next
+	if now := time.Now(); slot.Before(now) {
+		slot = now
+	}
+	limiter.next = slot.Add(interval)
+	limiter.mutex.Unlock()
+	time.Sleep(time.Until(slot))
+}
+
+// delay keeps the limiter from handing out slots for a while, for when Telegram asks to slow down.
+func (limiter *rateLimiter) delay(duration time.Duration) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	if until := time.Now().Add(duration); until.After(limiter.next) {
+		limiter.next = until
+	}
+}
+
+var globalLimiter = &rateLimiter{}
+
+// perInterval returns the time between messages to send count of them in period, or zero without a limit.
+func perInterval(period time.Duration, count int) time.Duration {
+	if count <= 0 {
+		return 0
+	}
+	return period / time.Duration(count)
+}
+
+// chatSendInterval is the time between messages to a chat, groups having a stricter limit than private chats.
+func chatSendInterval(chatID int64) time.Duration {
+	limits := getConfig().RateLimits
+	if chatID < 0 {
+		return perInterval(time.Minute, limits.GroupChatPerMinute)
+	}
+	return perInterval(time.Minute, limits.PrivateChatPerMinute)
+}
+
+// outbox is the queue of a chat. pending counts the messages handed to it and not taken by runOutbox yet, including
+// those still waiting for room in the queue, so the outbox is not dropped while someone is about to use it.
+type outbox struct {
+	queue   chan outgoing
+	pending int
+}
+
+var outboxesMutex sync.Mutex
+var outboxes map[int64]*outbox = make(map[int64]*outbox)
+
+// deliver queues a message in the outbox of its chat and waits until it is sent or given up on. Only this chat waits
+// when its queue is full.
+func deliver(chatID int64, chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	result := make(chan delivery, 1)
+
+	outboxesMutex.Lock()
+	box, exists := outboxes[chatID]
+	if !exists {
+		box = &outbox{queue: make(chan outgoing, 100)}
+		outboxes[chatID] = box
+		go runOutbox(chatID, box)
+	}
+	box.pending++
+	outboxesMutex.Unlock()
+
+	box.queue <- outgoing{chattable: chattable, result: result}
+	sent := <-result
+	return sent.message, sent.err
+}
+
+// runOutbox sends the messages of a chat in order, stopping after a while without messages.
+func runOutbox(chatID int64, box *outbox) {
+	limiter := &rateLimiter{}
+
+	for {
+		select {
+		case item := <-box.queue:
+			outboxesMutex.Lock()
+			box.pending--
+			outboxesMutex.Unlock()
+			message, err := sendWithRetries(chatID, limiter, item.chattable)
+			item.result <- delivery{message: message, err: err}
+		case <-time.After(outboxIdleTimeout):
+			outboxesMutex.Lock()
+			if box.pending == 0 {
+				delete(outboxes, chatID)
+				outboxesMutex.Unlock()
+				return
+			}
+			outboxesMutex.Unlock()
+		}
+	}
+}
+
+func sendWithRetries(chatID int64, limiter *rateLimiter, chattable tgbotapi.Chattable) (tgbotapi.Message, error) {
+	backoff := initialDeliveryBackoff
+	for attempt := 1; ; attempt++ {
+		limiter.wait(chatSendInterval(chatID))
+		globalLimiter.wait(perInterval(time.Second, getConfig().RateLimits.GlobalPerSecond))
+		message, err := bot.Send(chattable)
+		if err == nil {
+			atomic.AddInt64(&deliveryMetrics.Sent, 1)
+			return message, nil
+		}
+
+		retryAfter, retry := retryDelay(err, backoff)
+		if !retry || attempt == maxDeliveryAttempts {
+			atomic.AddInt64(&deliveryMetrics.Failed, 1)
+			slog.Error("error delivering a message", "chat_id", chatID, "attempts", attempt, "error", err)
+			return message, err
+		}
+
+		atomic.AddInt64(&deliveryMetrics.Retried, 1)
+		if apiError, ok := err.(tgbotapi.Error); ok && apiError.RetryAfter > 0 {
+			atomic.AddInt64(&deliveryMetrics.RateLimited, 1)
+		}
+		slog.Warn("error delivering a message, retrying", "chat_id", chatID, "retry_in", retryAfter, "error", err)
+		limiter.delay(retryAfter)
+		backoff *= 2
+	}
+}
+
+// retryDelay tells whether a failed send is worth retrying and how long to wait before doing it. Uploads report what
+// Telegram answered as plain errors, so their message is checked like the one of API errors.
+func retryDelay(err error, backoff time.Duration) (time.Duration, bool) {
+	var networkError net.Error
+	if errors.As(err, &networkError) {
+		// The request did not reach Telegram or timed out.
+		return backoff, true
+	}
+	description := err.Error()
+	if apiError, isAPIError := err.(tgbotapi.Error); isAPIError {
+		if apiError.RetryAfter > 0 {
+			return time.Duration(apiError.RetryAfter) * time.Second, true
+		}
+		description = apiError.Message
+	}
+	for _, transient := range transientErrors {
+		if strings.Contains(description, transient) {
+			return backoff, true
+		}
+	}
+	return 0, false
+}
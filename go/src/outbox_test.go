@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryDelay(t *testing.T) {
+	backoff := 2 * time.Second
+	for _, test := range []struct {
+		name  string
+		err   error
+		delay time.Duration
+		retry bool
+	}{
+		{"network", &url.Error{Op: "Post", URL: "https://api.telegram.org", Err: timeoutError{}}, backoff, true},
+		{"rate limited", tgbotapi.Error{Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 7}}, 7 * time.Second, true},
+		{"server error", tgbotapi.Error{Message: "Bad Gateway"}, backoff, true},
+		{"bad request", tgbotapi.Error{Message: "Bad Request: chat not found"}, 0, false},
+		{"upload rejected", errors.New("Bad Request: file must be non-empty"), 0, false},
+		{"upload rate limited", errors.New("Too Many Requests: retry after 3"), backoff, true},
+		{"unreadable answer", errors.New("invalid character '<' looking for beginning of value"), 0, false},
+	} {
+		delay, retry := retryDelay(test.err, backoff)
+		if delay != test.delay || retry != test.retry {
+			t.Errorf("%s: expected %v, %v, got %v, %v", test.name, test.delay, test.retry, delay, retry)
+		}
+	}
+}
+
+// sentTo returns the texts of the messages the bot tried to send to a chat, failed or not.
+func sentTo(chatID int64) []string {
+	texts := make([]string, 0)
+	for _, call := range fake.calls("sendMessage") {
+		if call.chatID() == chatID {
+			texts = append(texts, call.text())
+		}
+	}
+	return texts
+}
+
+func TestDeliverWaitsOutRateLimits(t *testing.T) {
+	chat := newTestGroup(t)
+	fake.failNext(chat.chat.ID, tgbotapi.APIResponse{Ok: false, ErrorCode: 429, Description: "Too Many Requests: retry after 1",
+		Parameters: &tgbotapi.ResponseParameters{RetryAfter: 1}})
+
+	start := time.Now()
+	if _, err := deliver(chat.chat.ID, tgbotapi.NewMessage(chat.chat.ID, "hola")); err != nil {
+		t.Fatalf("expected the message to go through after the wait, got %v", err)
+	}
+	if waited := time.Since(start); waited < time.Second {
+		t.Errorf("expected the retry to wait the second Telegram asked for, it waited %v", waited)
+	}
+	assertContains(t, chat.expect().text(), "hola")
+	if texts := sentTo(chat.chat.ID); len(texts) != 2 {
+		t.Errorf("expected a failed attempt and a retry, got %q", texts)
+	}
+}
+
+func TestDeliverGivesUpOnPermanentErrors(t *testing.T) {
+	chat := newTestGroup(t)
+	fake.failNext(chat.chat.ID, tgbotapi.APIResponse{Ok: false, ErrorCode: 400, Description: "Bad Request: chat not found"})
+
+	if _, err := deliver(chat.chat.ID, tgbotapi.NewMessage(chat.chat.ID, "hola")); err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Fatalf("expected the error of Telegram, got %v", err)
+	}
+	fake.expectSilence(t)
+	if texts := sentTo(chat.chat.ID); len(texts) != 1 {
+		t.Errorf("expected a single attempt, got %q", texts)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+)
+
+// headcount returns the number of people the cost of the game is split across.
+func (game Game) headcount() int {
+	return len(game.Players) + len(game.Guests)
+}
+
+// share returns what each player or guest has to pay, rounded up so the field is always covered.
+func (game Game) share() int {
+	count := game.headcount()
+	if game.Cost <= 0 || count == 0 {
+		return 0
+	}
+	return (game.Cost + count - 1) / count
+}
+
+// chargedShares returns how many shares a player is responsible for: their own plus one per guest they invited.
+func (game Game) chargedShares(playerID int) int {
+	shares := 0
+	if contains(game.Players, playerID) {
+		shares++
+	}
+	return shares + len(game.guestsInvitedBy(playerID))
+}
+
+// Payment is an amount a player paid for a game, for themselves and their guests.
+type Payment struct {
+	PlayerID int
+	Amount   int
+}
+
+// paidBy adds up what a player paid for the game.
+func (game Game) paidBy(playerID int) int {
+	paid := 0
+	for _, payment := range game.Payments {
+		if payment.PlayerID == playerID {
+			paid += payment.Amount
+		}
+	}
+	return paid
+}
+
+// balanceOf is what a player still owes for the game, negative when they paid more than their current shares.
+func (game Game) balanceOf(playerID int) int {
+	return game.share()*game.chargedShares(playerID) - game.paidBy(playerID)
+}
+
+func (game Game) amountOwedBy(playerID int) int {
+	if balance := game.balanceOf(playerID); balance > 0 {
+		return balance
+	}
+	return 0
+}
+
+// debtors returns every player that still owes money for the game, in sign-up order.
+func (game Game) debtors() []int {
+	result := make([]int, 0)
+	if game.share() == 0 {
+		return result
+	}
+	candidates := append([]int{}, game.Players...)
+	for _, guest := range game.Guests {
+		candidates = append(candidates, guest.InviterID)
+	}
+	for _, playerID := range candidates {
+		if !contains(result, playerID) && game.amountOwedBy(playerID) > 0 {
+			result = append(result, playerID)
+		}
+	}
+	return result
+}
+
+// getChatBalances adds up the balance of every player across the pending games of a chat and returns those who owe
+// money. Paying more than a share in one game counts towards what the player owes in others. A cancelled game was
+// never played, so it charges nobody.
+func getChatBalances(chatID int64) map[int]int {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	balances := make(map[int]int)
+	for _, game := range games {
+		if !game.Active || game.ChatID != chatID || game.Cost <= 0 {
+			continue
+		}
+		people := append([]int{}, game.Players...)
+		for _, guest := range game.Guests {
+			people = append(people, guest.InviterID)
+		}
+		for _, payment := range game.Payments {
+			people = append(people, payment.PlayerID)
+		}
+		counted := make([]int, 0, len(people))
+		for _, playerID := range people {
+			if !contains(counted, playerID) {
+				counted = append(counted, playerID)
+				balances[playerID] += game.balanceOf(playerID)
+			}
+		}
+	}
+	for playerID, balance := range balances {
+		if balance <= 0 {
+			delete(balances, playerID)
+		}
+	}
+	return balances
+}
+
+func sortedBalanceIDs(balances map[int]int) []int {
+	ids := make([]int, 0, len(balances))
+	for id := range balances {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if balances[ids[i]] != balances[ids[j]] {
+			return balances[ids[i]] > balances[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+func formatAmount(amount int) string {
+	return "$" + strconv.Itoa(amount)
+}
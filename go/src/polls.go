@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// DatePoll is a vote among several dates for a game, shown as a message with one button per option.
+type DatePoll struct {
+	Options []string
+	// Votes maps each user to the index of the option they voted for.
+	Votes     map[int]int
+	Deadline  time.Time
+	MessageID int
+}
+
+// datePollDuration is how long a poll stays open unless the organizer gives a deadline, up to maxDatePollDuration.
+var datePollDuration = 24 * time.Hour
+var maxDatePollDuration = 7 * 24 * time.Hour
+
+func (poll DatePoll) count(option int) int {
+	total := 0
+	for _, vote := range poll.Votes {
+		if vote == option {
+			total++
+		}
+	}
+	return total
+}
+
+// withVote returns the poll with a user's vote changed. The votes are copied, since older copies of the game can
+// still be reading them.
+func (poll DatePoll) withVote(userID int, option int) DatePoll {
+	votes := make(map[int]int, len(poll.Votes)+1)
+	for user, vote := range poll.Votes {
+		votes[user] = vote
+	}
+	votes[userID] = option
+	poll.Votes = votes
+	return poll
+}
+
+// winner returns the most voted option, the earliest one on a tie, or false if nobody voted.
+func (poll DatePoll) winner() (int, bool) {
+	best := -1
+	for i := range poll.Options {
+		if poll.count(i) > 0 && (best == -1 || poll.count(i) > poll.count(best)) {
+			best = i
+		}
+	}
+	return best, best != -1
+}
+
+func (poll DatePoll) text(language string, gameId int) string {
+	response := emojiCalendar + " " + tr(language, "poll.title", "game", gameId, "date", poll.Deadline.Format("02/01"), "time", poll.Deadline.Format("15:04")) + "\n"
+	for i, option := range poll.Options {
+		response += "\n" + unicodeBulletPoint + " " + trn(language, "poll.option", poll.count(i), "option", option)
+	}
+	return response
+}
+
+func (poll DatePoll) keyboard(gameId int) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(poll.Options))
+	for i, option := range poll.Options {
+		data := fmt.Sprintf("fecha:%d:%d", gameId, i)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(option, data)))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// splitDateOption separates the time from an option like "martes 21:00" so it can go to the game schedule.
+func splitDateOption(option string) ([]string, []string) {
+	words := strings.Fields(option)
+	if len(words) > 1 {
+		last := words[len(words)-1]
+		if _, _, err := parseTimeOfDay(last); err == nil && (strings.Contains(last, ":") || strings.HasSuffix(strings.ToLower(last), "hs")) {
+			return words[:len(words)-1], []string{last}
+		}
+	}
+	return words, nil
+}
+
+func handleVotarFechaCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	var response string
+	game := args.Game
+
+	options := make([]string, 0)
+	for _, option := range strings.Split(args.Text("opcion 1 | opcion 2"), "|") {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	duration := datePollDuration
+	if args.Has("plazo") {
+		duration = args.Duration("plazo")
+	}
+	if game.DatePoll != nil {
+		response = tr(args.Language, "poll.already_open", "game", game.Id)
+	} else if len(options) < 2 || len(options) > 10 {
+		response = tr(args.Language, "poll.options_missing", "name", message.From.FirstName)
+	} else if duration > maxDatePollDuration {
+		response = tr(args.Language, "poll.deadline_too_long", "name", message.From.FirstName, "days", int(maxDatePollDuration/(24*time.Hour)))
+	} else {
+		poll := DatePoll{
+			Options:  options,
+			Votes:    make(map[int]int),
+			Deadline: clock().Add(duration),
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, poll.text(args.Language, game.Id))
+		msg.ReplyMarkup = poll.keyboard(game.Id)
+		sent, err := sendText(msg)
+		if err != nil {
+			response = tr(args.Language, "poll.send_failed", "error", err.Error())
+		} else {
+			poll.MessageID = sent.MessageID
+			game.DatePoll = &poll
+			updateGame(game.Id, game)
+			return
+		}
+	}
+	respondToMessage(message, response)
+}
+
+func handleCerrarVotacionCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	if args.Game.DatePoll == nil {
+		respondToMessage(message, tr(args.Language, "poll.not_open", "game", args.Game.Id))
+		return
+	}
+	closeDatePoll(args.Game.Id)
+}
+
+func handleDateVoteCallback(bot TelegramClient, query *tgbotapi.CallbackQuery) {
+	parts := strings.Split(query.Data, ":")
+	if len(parts) != 3 {
+		return
+	}
+	gameId, _ := strconv.Atoi(parts[1])
+	option, _ := strconv.Atoi(parts[2])
+
+	language := defaultLanguage
+	if query.Message != nil {
+		language = chatLanguage(query.Message.Chat.ID)
+	}
+
+	mutex.Lock()
+	game, exists := games[gameId]
+	if !exists || game.DatePoll == nil || option < 0 || option >= len(game.DatePoll.Options) {
+		mutex.Unlock()
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, tr(language, "poll.closed")))
+		return
+	}
+	poll := game.DatePoll.withVote(query.From.ID, option)
+	game.DatePoll = &poll
+	games[gameId] = game
+	mutex.Unlock()
+
+	edit := tgbotapi.NewEditMessageText(game.ChatID, poll.MessageID, poll.text(language, gameId))
+	keyboard := poll.keyboard(gameId)
+	edit.ReplyMarkup = &keyboard
+	editText(edit)
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, plainText(tr(language, "poll.voted", "option", poll.Options[option]))))
+}
+
+// closeDatePoll ends the vote of a game, writes the winning option into its date and time, and notifies the chat.
+func closeDatePoll(gameId int) {
+	mutex.Lock()
+	game, exists := games[gameId]
+	if !exists || game.DatePoll == nil {
+		mutex.Unlock()
+		return
+	}
+	poll := *game.DatePoll
+	game.DatePoll = nil
+	winner, hasWinner := poll.winner()
+	if hasWinner {
+		date, schedule := splitDateOption(poll.Options[winner])
+		game.Date = date
+		if schedule != nil {
+			game.Schedule = schedule
+		}
+	}
+	games[gameId] = game
+	mutex.Unlock()
+
+	language := chatLanguage(game.ChatID)
+	editText(tgbotapi.NewEditMessageText(game.ChatID, poll.MessageID, poll.text(language, gameId)+"\n\n"+tr(language, "poll.closed_footer")))
+	if hasWinner {
+		sendMessage(game.ChatID, emojiCalendar+" "+trn(language, "poll.winner", poll.count(winner), "game", gameId, "option", poll.Options[winner]))
+	} else {
+		sendMessage(game.ChatID, tr(language, "poll.no_votes", "game", gameId))
+	}
+}
+
+func closeExpiredDatePolls(now time.Time) {
+	expired := make([]int, 0)
+	mutex.Lock()
+	for id, game := range games {
+		if game.DatePoll != nil && !now.Before(game.DatePoll.Deadline) {
+			expired = append(expired, id)
+		}
+	}
+	mutex.Unlock()
+
+	for _, id := range expired {
+		closeDatePoll(id)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// A recording is a JSONL file with the updates the bot received and the calls it made to Telegram, one per line in
+// the order they happened. The bot writes one when Config.Recording is set, and `fulbot replay` plays it back.
+
+// RecordEntry is a line of a recording: either an update or a call to the Bot API.
+type RecordEntry struct {
+	Time   time.Time        `json:"time"`
+	Update *tgbotapi.Update `json:"update,omitempty"`
+	// Method and Params are a call of the bot. MessageID is the message Telegram answered with, if any.
+	Method    string     `json:"method,omitempty"`
+	Params    url.Values `json:"params,omitempty"`
+	MessageID int        `json:"message_id,omitempty"`
+	// Member is the answer to a getChatMember call, so replays see the same names and administrators.
+	Member *tgbotapi.ChatMember `json:"member,omitempty"`
+}
+
+// recordedMethods are the calls worth keeping: what users see and what the bot learns about them.
+var recordedMethods = map[string]bool{
+	"sendMessage":         true,
+	"editMessageText":     true,
+	"sendVenue":           true,
+	"answerCallbackQuery": true,
+	"getChatMember":       true,
+}
+
+type recorder struct {
+	mutex   sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// activeRecorder is nil unless the bot is recording.
+var activeRecorder *recorder
+
+// startRecording appends everything the bot receives and sends from now on to a file.
+func startRecording(filename string) error {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	activeRecorder = &recorder{file: file, encoder: json.NewEncoder(file)}
+	slog.Info("recording updates and messages", "file", filename)
+	return nil
+}
+
+func (recorder *recorder) write(entry RecordEntry) {
+	if recorder == nil {
+		return
+	}
+	entry.Time = clock()
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	if err := recorder.encoder.Encode(entry); err != nil {
+		slog.Error("error writing to the recording", "error", err)
+	}
+}
+
+// recordingTransport writes the Bot API calls that go through it to the recording, along with what Telegram answered.
+type recordingTransport struct {
+	next http.RoundTripper
+}
+
+func (transport recordingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	method := path.Base(request.URL.Path)
+	if !recordedMethods[method] || request.Body == nil {
+		return transport.next.RoundTrip(request)
+	}
+
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	params, _ := url.ParseQuery(string(body))
+
+	response, err := transport.next.RoundTrip(request)
+	if err != nil {
+		return response, err
+	}
+	answer, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(answer))
+	if err != nil {
+		return response, nil
+	}
+
+	entry := RecordEntry{Method: method, Params: params}
+	var apiResponse tgbotapi.APIResponse
+	if json.Unmarshal(answer, &apiResponse) == nil && apiResponse.Ok {
+		if method == "getChatMember" {
+			var member tgbotapi.ChatMember
+			if json.Unmarshal(apiResponse.Result, &member) == nil {
+				entry.Member = &member
+			}
+		} else {
+			var message tgbotapi.Message
+			if json.Unmarshal(apiResponse.Result, &message) == nil {
+				entry.MessageID = message.MessageID
+			}
+		}
+	}
+	activeRecorder.write(entry)
+	return response, nil
+}
+
+// readRecording loads every entry of a recording.
+func readRecording(filename string) ([]RecordEntry, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make([]RecordEntry, 0)
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var entry RecordEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
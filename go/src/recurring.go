@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// RecurringGame is a weekly template the scheduler turns into a regular game some days before it is played.
+type RecurringGame struct {
+	Id          int
+	OrganizerID int
+	Weekday     time.Weekday
+	Hour        int
+	Minute      int
+	Size        string
+	Address     []string
+	DaysAhead   int
+	// KeepRegulars pre-enrolls the players of the previous instance in the new one.
+	KeepRegulars bool
+	LastGameID   int
+	LastDate     time.Time
+}
+
+var defaultRecurringDaysAhead = 3
+
+var errInvalidWeekday = errors.New("invalid weekday")
+var errInvalidTime = errors.New("invalid time of day")
+
+func weekdayName(language string, weekday time.Weekday) string {
+	return tr(language, "weekday."+strconv.Itoa(int(weekday)))
+}
+
+// parseWeekday accepts the name of a day in any supported language, with or without accents.
+func parseWeekday(text string) (time.Weekday, error) {
+	replacer := strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ç", "c")
+	normalized := replacer.Replace(strings.ToLower(text))
+	for language := range catalogs {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if normalized == replacer.Replace(strings.ToLower(weekdayName(language, day))) {
+				return day, nil
+			}
+		}
+	}
+	return 0, errInvalidWeekday
+}
+
+// parseTimeOfDay accepts "21:00", "21hs" or "21".
+func parseTimeOfDay(text string) (int, int, error) {
+	text = strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(text), "hs"), "h")
+	parts := strings.SplitN(text, ":", 2)
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, 0, errInvalidTime
+	}
+	minute := 0
+	if len(parts) == 2 {
+		minute, err = strconv.Atoi(parts[1])
+		if err != nil || minute < 0 || minute > 59 {
+			return 0, 0, errInvalidTime
+		}
+	}
+	return hour, minute, nil
+}
+
+func (recurring RecurringGame) schedule() string {
+	return fmt.Sprintf("%02d:%02d", recurring.Hour, recurring.Minute)
+}
+
+// nextOccurrence returns the first date after now when the game is played.
+func (recurring RecurringGame) nextOccurrence(now time.Time) time.Time {
+	days := (int(recurring.Weekday) - int(now.Weekday()) + 7) % 7
+	next := time.Date(now.Year(), now.Month(), now.Day()+days, recurring.Hour, recurring.Minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+func (recurring RecurringGame) isDue(now time.Time) bool {
+	next := recurring.nextOccurrence(now)
+	return !next.Equal(recurring.LastDate) && next.Sub(now) <= time.Duration(recurring.DaysAhead)*24*time.Hour
+}
+
+func (recurring RecurringGame) describe(language string) string {
+	description := strconv.Itoa(recurring.Id) + ". " + tr(language, "recurring.every", "day", weekdayName(language, recurring.Weekday), "time", recurring.schedule(), "size", recurring.Size)
+	if len(recurring.Address) > 0 {
+		description += " " + tr(language, "recurring.at", "address", strings.Join(recurring.Address, " "))
+	}
+	description += ". " + trn(language, "recurring.days_ahead", recurring.DaysAhead)
+	if recurring.KeepRegulars {
+		description += " " + tr(language, "recurring.keeps_regulars")
+	}
+	return description + "."
+}
+
+// instantiateRecurringGames creates the games of every template that is due and announces them in their chats.
+func instantiateRecurringGames(now time.Time) {
+	chatsMutex.Lock()
+	pending := make([]ChatSettings, 0)
+	for _, chat := range chats {
+		pending = append(pending, chat.clone())
+	}
+	chatsMutex.Unlock()
+
+	for _, chat := range pending {
+		for _, recurring := range chat.RecurringGames {
+			if !recurring.isDue(now) {
+				continue
+			}
+			date := recurring.nextOccurrence(now)
+			game, err := instantiateRecurringGame(chat.ID, recurring, date)
+			if err != nil {
+				// The week is marked as done all the same, so the scheduler does not try again every time it runs.
+				slog.Warn("skipping a recurring game", "chat_id", chat.ID, "recurring_id", recurring.Id, "size", recurring.Size, "error", err)
+				markRecurringGameCreated(chat.ID, recurring.Id, 0, date)
+				continue
+			}
+			markRecurringGameCreated(chat.ID, recurring.Id, game.Id, date)
+
+			sendMessage(chat.ID, renderTemplate(chat.ID, templateReminder, TemplateData{Game: gameTemplateData(game, false)}))
+		}
+	}
+}
+
+// instantiateRecurringGame creates the game of a template, unless its size is no longer valid, like after lowering
+// max_game_size or importing a broken template.
+func instantiateRecurringGame(chatID int64, recurring RecurringGame, date time.Time) (Game, error) {
+	maxPlayers, err := getMaxPlayersByTamano(recurring.Size)
+	if err != nil {
+		return Game{}, err
+	}
+	game := newGame(chatID, recurring.OrganizerID, recurring.Size, maxPlayers)
+	game.Date = []string{weekdayName(chatLanguage(chatID), date.Weekday()), date.Format("02/01")}
+	game.Schedule = []string{recurring.schedule()}
+	if len(recurring.Address) > 0 {
+		applyVenue(&game, append([]string{}, recurring.Address...))
+	}
+	if recurring.KeepRegulars {
+		if previous, exists := getGame(recurring.LastGameID); exists {
+			for _, playerID := range previous.Players {
+				if !game.isFull() {
+					game.Players = append(game.Players, playerID)
+				}
+			}
+		}
+	}
+	return createGame(game), nil
+}
+
+// markRecurringGameCreated records the game created for a date, keeping the last one when gameID is zero because
+// the template was skipped.
+func markRecurringGameCreated(chatID int64, recurringID int, gameID int, date time.Time) {
+	chatsMutex.Lock()
+	defer chatsMutex.Unlock()
+
+	chat := chats[chatID]
+	for i := range chat.RecurringGames {
+		if chat.RecurringGames[i].Id == recurringID {
+			if gameID != 0 {
+				chat.RecurringGames[i].LastGameID = gameID
+			}
+			chat.RecurringGames[i].LastDate = date
+		}
+	}
+	chats[chatID] = chat
+}
+
+func findRecurringGame(chat ChatSettings, text string) (int, bool) {
+	id, err := strconv.Atoi(text)
+	if err != nil {
+		return -1, false
+	}
+	for i, recurring := range chat.RecurringGames {
+		if recurring.Id == id {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// recurringOptions are the message keys of the /partidofijo options that work on an existing recurring game.
+var recurringOptions = []string{"option.delete", "option.repeat", "option.ahead"}
+
+func handlePartidoFijoCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	chat := getChatSettings(message.Chat.ID)
+	language := args.Language
+	name := message.From.FirstName
+	var response string
+
+	// The arguments change meaning with the option, so they are read as plain words.
+	params := strings.Fields(message.CommandArguments())
+	action := args.Text("dia")
+	option := ""
+	for _, key := range recurringOptions {
+		if isOption(language, action, key) {
+			option = key
+		}
+	}
+
+	switch {
+	case action == "":
+		if len(chat.RecurringGames) == 0 {
+			response = tr(language, "recurring.empty")
+		} else {
+			response = tr(language, "recurring.list") + "\n"
+			for _, recurring := range chat.RecurringGames {
+				response += unicodeBulletPoint + " " + recurring.describe(language) + "\n"
+			}
+		}
+	case option != "":
+		if len(params) < 2 {
+			response = tr(language, "recurring.number_missing", "name", name, "option", action)
+			break
+		}
+		index, found := findRecurringGame(chat, params[1])
+		if !found {
+			response = tr(language, "recurring.not_found", "name", name)
+		} else if chat.RecurringGames[index].OrganizerID != message.From.ID {
+			response = tr(language, "recurring.forbidden")
+		} else if option == "option.delete" {
+			chat.RecurringGames = append(chat.RecurringGames[:index], chat.RecurringGames[index+1:]...)
+			updateChatSettings(chat)
+			response = tr(language, "recurring.deleted", "recurring", params[1])
+		} else if option == "option.repeat" {
+			chat.RecurringGames[index].KeepRegulars = !chat.RecurringGames[index].KeepRegulars
+			updateChatSettings(chat)
+			if chat.RecurringGames[index].KeepRegulars {
+				response = tr(language, "recurring.repeat_on")
+			} else {
+				response = tr(language, "recurring.repeat_off")
+			}
+		} else if len(params) < 3 {
+			response = tr(language, "recurring.days_missing", "name", name)
+		} else if days, err := strconv.Atoi(params[2]); err != nil || days < 1 || days > 6 {
+			response = tr(language, "recurring.invalid_days", "days", params[2])
+		} else {
+			chat.RecurringGames[index].DaysAhead = days
+			updateChatSettings(chat)
+			response = trn(language, "recurring.days_set", days, "recurring", params[1])
+		}
+	default:
+		if len(params) < 3 {
+			response = tr(language, "recurring.params_missing", "name", name)
+			break
+		}
+		weekday, err := parseWeekday(params[0])
+		if err != nil {
+			response = tr(language, "recurring.invalid_weekday", "day", params[0])
+			break
+		}
+		hour, minute, err := parseTimeOfDay(params[1])
+		if err != nil {
+			response = tr(language, "time.invalid", "time", params[1])
+			break
+		}
+		if _, err := getMaxPlayersByTamano(params[2]); err != nil {
+			response = tr(language, "recurring.error", "error", translateError(language, err))
+			break
+		}
+		recurring := RecurringGame{
+			Id:          1,
+			OrganizerID: message.From.ID,
+			Weekday:     weekday,
+			Hour:        hour,
+			Minute:      minute,
+			Size:        params[2],
+			DaysAhead:   defaultRecurringDaysAhead,
+		}
+		if len(params) > 3 {
+			recurring.Address = params[3:]
+		}
+		for _, existing := range chat.RecurringGames {
+			if existing.Id >= recurring.Id {
+				recurring.Id = existing.Id + 1
+			}
+		}
+		chat.RecurringGames = append(chat.RecurringGames, recurring)
+		updateChatSettings(chat)
+		response = tr(language, "recurring.saved", "recurring", HTML(recurring.describe(language)), "id", recurring.Id)
+	}
+
+	respondToMessage(message, response)
+}
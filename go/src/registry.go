@@ -0,0 +1,267 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// ArgumentKind tells the command framework how to parse and validate an argument.
+type ArgumentKind int
+
+const (
+	// ArgGame is the number of a pending game, resolved to the game itself.
+	ArgGame ArgumentKind = iota
+	// ArgNumber is a non negative integer.
+	ArgNumber
+	// ArgWord is a single word.
+	ArgWord
+	// ArgText takes the rest of the message, so it must be the last argument.
+	ArgText
+	// ArgUser is the author of the replied message or a text mention, falling back to the sender. It consumes no words.
+	ArgUser
+	// ArgDuration is a positive length of time with its unit, like 48h or 90m. When optional, a word that is not a
+	// duration is left for the next argument.
+	ArgDuration
+)
+
+type Argument struct {
+	Name     string
+	Kind     ArgumentKind
+	Optional bool
+}
+
+// CommandScope tells in which kind of chats a command is offered in the Telegram command menu.
+type CommandScope int
+
+const (
+	ScopeAllChats CommandScope = iota
+	ScopeGroups
+	ScopePrivate
+)
+
+type Permission int
+
+const (
+	PermissionAnyone Permission = iota
+	// PermissionOrganizer restricts the command to the organizer of the game given as argument.
+	PermissionOrganizer
+	// PermissionChatAdmin restricts the command to the administrators of the group.
+	PermissionChatAdmin
+)
+
+// Command declares a bot command: how it is called, what it expects and who can use it.
+// Its help text is the "help.<name>" message and each argument is shown as the "arg.<name>" message.
+type Command struct {
+	Name string
+	// Names are the command names in other languages, also accepted in every chat.
+	Names      map[string]string
+	Aliases    []string
+	Emoji      string
+	Arguments  []Argument
+	Permission Permission
+	Scope      CommandScope
+	Handler    CommandHandlerFunc
+}
+
+// CommandArgs holds the arguments of a command already parsed and validated.
+type CommandArgs struct {
+	Game Game
+	User *tgbotapi.User
+	// Language is the language of the chat the command was sent from.
+	Language  string
+	values    map[string]string
+	numbers   map[string]int
+	durations map[string]time.Duration
+}
+
+func (args CommandArgs) Has(name string) bool {
+	_, exists := args.values[name]
+	return exists
+}
+
+func (args CommandArgs) Text(name string) string {
+	return args.values[name]
+}
+
+func (args CommandArgs) Words(name string) []string {
+	return strings.Fields(args.values[name])
+}
+
+func (args CommandArgs) Number(name string) int {
+	return args.numbers[name]
+}
+
+func (args CommandArgs) Duration(name string) time.Duration {
+	return args.durations[name]
+}
+
+// name returns how the command is called in a language.
+func (command Command) name(language string) string {
+	if name, exists := command.Names[language]; exists {
+		return name
+	}
+	return command.Name
+}
+
+func (command Command) help(language string) string {
+	return tr(language, "help."+command.Name)
+}
+
+func (argument Argument) label(language string) string {
+	return tr(language, "arg."+argument.Name)
+}
+
+func (command Command) usage(language string) string {
+	usage := "/" + command.name(language)
+	for _, argument := range command.Arguments {
+		usage += " [" + argument.label(language) + "]"
+	}
+	return usage
+}
+
+// parseArguments validates the message against the declared arguments, returning the message to show the user on failure.
+func parseArguments(command Command, message *tgbotapi.Message) (CommandArgs, string) {
+	language := chatLanguage(message.Chat.ID)
+	args := CommandArgs{
+		User:      message.From,
+		Language:  language,
+		values:    make(map[string]string),
+		numbers:   make(map[string]int),
+		durations: make(map[string]time.Duration),
+	}
+	words := strings.Fields(message.CommandArguments())
+	name := message.From.FirstName
+	commandName := command.name(language)
+
+	for _, argument := range command.Arguments {
+		if argument.Kind == ArgUser {
+			if user := mentionedUser(message); user != nil {
+				args.User = user
+			} else if !argument.Optional {
+				return args, tr(language, "args.user_missing", "name", name, "command", commandName, "argument", argument.label(language), "usage", command.usage(language))
+			}
+			continue
+		}
+
+		if len(words) == 0 {
+			if argument.Optional {
+				continue
+			}
+			return args, tr(language, "args.missing", "name", name, "command", commandName, "argument", argument.label(language), "usage", command.usage(language))
+		}
+		if argument.Kind == ArgDuration && argument.Optional {
+			if _, err := time.ParseDuration(words[0]); err != nil {
+				continue
+			}
+		}
+
+		value := words[0]
+		words = words[1:]
+		if argument.Kind == ArgText {
+			value = strings.Join(append([]string{value}, words...), " ")
+			words = nil
+		}
+		args.values[argument.Name] = value
+
+		switch argument.Kind {
+		case ArgGame:
+			gameId, err := strconv.Atoi(value)
+			if err != nil {
+				return args, tr(language, "args.invalid_game", "value", value)
+			}
+			game, exists := getGame(gameId)
+			if !exists || !game.Active {
+				return args, tr(language, "args.game_not_found", "name", name)
+			}
+			args.Game = game
+			args.numbers[argument.Name] = gameId
+		case ArgNumber:
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 0 {
+				return args, tr(language, "args.invalid_number", "value", value, "argument", argument.label(language))
+			}
+			args.numbers[argument.Name] = number
+		case ArgDuration:
+			duration, err := time.ParseDuration(value)
+			if err != nil || duration <= 0 {
+				return args, tr(language, "args.invalid_duration", "value", value, "argument", argument.label(language))
+			}
+			args.durations[argument.Name] = duration
+		}
+	}
+
+	if command.Permission == PermissionOrganizer && args.Game.OrganizerID != message.From.ID && !isBotAdmin(message.From.ID) {
+		return args, tr(language, "args.organizer_only", "command", commandName)
+	}
+	if command.Permission == PermissionChatAdmin && !isChatAdmin(message.Chat, message.From.ID) {
+		return args, tr(language, "args.admin_only", "command", commandName)
+	}
+	return args, ""
+}
+
+// mentionedUser returns the user a message refers to, either by replying to them or with a text mention.
+func mentionedUser(message *tgbotapi.Message) *tgbotapi.User {
+	if message.ReplyToMessage != nil && message.ReplyToMessage.From != nil {
+		return message.ReplyToMessage.From
+	}
+	if message.Entities != nil {
+		for _, entity := range *message.Entities {
+			if entity.Type == "text_mention" && entity.User != nil {
+				return entity.User
+			}
+		}
+	}
+	return nil
+}
+
+func runCommand(bot TelegramClient, command Command, message *tgbotapi.Message) {
+	logger := messageLogger(message)
+	args, problem := parseArguments(command, message)
+	if problem != "" {
+		logger.Debug("invalid command arguments", "problem", problem)
+		commandsHandled.inc(command.Name, outcomeInvalidArguments)
+		respondToMessage(message, problem)
+		return
+	}
+	defer func() {
+		if problem := recover(); problem != nil {
+			commandsHandled.inc(command.Name, outcomePanic)
+			panic(problem)
+		}
+		commandsHandled.inc(command.Name, outcomeOK)
+	}()
+	if args.Game.Id != 0 {
+		logger = logger.With("game_id", args.Game.Id)
+	}
+	logger.Info("running command", "name", command.Name)
+	command.Handler(bot, message, args)
+}
+
+// indexCommands maps every name, translated name and alias to its command.
+func indexCommands(commands []Command) map[string]Command {
+	index := make(map[string]Command)
+	for _, command := range commands {
+		index[command.Name] = command
+		for _, name := range command.Names {
+			index[name] = command
+		}
+		for _, alias := range command.Aliases {
+			index[alias] = command
+		}
+	}
+	return index
+}
+
+// helpText lists the commands offered in the given scopes, the same ones published in the Telegram command menu.
+func helpText(commands []Command, scopes []CommandScope, language string) string {
+	lines := make([]string, 0, len(commands))
+	for _, command := range commands {
+		if command.visibleIn(scopes) {
+			lines = append(lines, command.Emoji+" "+command.usage(language)+" - "+command.help(language))
+		}
+	}
+	return tr(language, "help.title") + "\n\n" + strings.Join(lines, "\n")
+}
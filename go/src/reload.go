@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+)
+
+// The configuration is read again when its file changes or the bot gets SIGHUP. The admins, rate limits, game sizes,
+// templates, backups and log level change right away. The rest needs a restart: those settings keep their running value and
+// the reload says so. A configuration that does not load or validate is rejected and the running one stays.
+
+// configWatchInterval is how often the configuration file is checked for changes.
+var configWatchInterval = 5 * time.Second
+
+// restartSetting is a setting a reload cannot change, by its name in the configuration file.
+type restartSetting struct {
+	name  string
+	value func(*Config) interface{}
+}
+
+var restartSettings = []restartSetting{
+	{"token", func(config *Config) interface{} { return config.Token }},
+	{"storage", func(config *Config) interface{} { return config.Storage }},
+	{"timezone", func(config *Config) interface{} { return config.Timezone }},
+	{"language", func(config *Config) interface{} { return config.Language }},
+	{"webhook", func(config *Config) interface{} { return config.Webhook }},
+	{"max_game_size", func(config *Config) interface{} { return config.MaxGameSize }},
+	{"metrics_listen", func(config *Config) interface{} { return config.MetricsListen }},
+	{"admin_socket", func(config *Config) interface{} { return config.AdminSocket }},
+	{"log_format", func(config *Config) interface{} { return config.LogFormat }},
+	{"recording", func(config *Config) interface{} { return config.Recording }},
+}
+
+// reloadedConfig merges a freshly loaded configuration into the running one, keeping the settings that need a
+// restart. It returns the names of those that changed in the file.
+func reloadedConfig(current *Config, loaded *Config) (*Config, []string) {
+	ignored := make([]string, 0)
+	for _, setting := range restartSettings {
+		if !reflect.DeepEqual(setting.value(current), setting.value(loaded)) {
+			ignored = append(ignored, setting.name)
+		}
+	}
+
+	reloaded := *current
+	reloaded.Admins = loaded.Admins
+	reloaded.RateLimits = loaded.RateLimits
+	reloaded.GameSizes = loaded.GameSizes
+	reloaded.Templates = loaded.Templates
+	reloaded.Backups = loaded.Backups
+	reloaded.LogLevel = loaded.LogLevel
+	return &reloaded, ignored
+}
+
+// reloadConfig loads the configuration again with the arguments the bot started with and applies it.
+func reloadConfig(args []string, getenv func(string) string) error {
+	loaded, err := loadConfig(args, getenv)
+	if err != nil {
+		return fmt.Errorf("keeping the current configuration: %w", err)
+	}
+	reloaded, ignored := reloadedConfig(getConfig(), loaded)
+	if err := reloaded.validate(); err != nil {
+		return fmt.Errorf("keeping the current configuration: %w", err)
+	}
+	if len(ignored) > 0 {
+		slog.Warn("some settings only change on restart", "settings", ignored)
+	}
+	setConfig(reloaded)
+	logLevel.Set(parseLogLevel(reloaded.LogLevel))
+	slog.Info("configuration reloaded", "config", reloaded.String())
+	return nil
+}
+
+// watchConfig reloads the configuration on SIGHUP and whenever its file is modified.
+func watchConfig(args []string) {
+	hangups := make(chan os.Signal, 1)
+	signal.Notify(hangups, syscall.SIGHUP)
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+
+	filename := getConfig().file
+	modified := modificationTime(filename)
+	for {
+		select {
+		case <-hangups:
+			slog.Info("got SIGHUP, reloading the configuration")
+		case <-ticker.C:
+			current := modificationTime(filename)
+			if current.Equal(modified) {
+				continue
+			}
+			modified = current
+			slog.Info("configuration file changed, reloading it", "file", filename)
+		}
+		if err := reloadConfig(args, os.Getenv); err != nil {
+			slog.Error("error reloading the configuration", "error", err)
+		}
+	}
+}
+
+// modificationTime returns when a file was last written, or the zero time if it does not exist.
+func modificationTime(filename string) time.Time {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"html"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Messages are sent with Telegram's HTML parse mode. Catalog messages are written in HTML and tr escapes
+// every parameter, so user content like names or addresses can never break the formatting.
+
+// HTML is text already escaped for Telegram, passed to tr as a parameter that must not be escaped again.
+type HTML string
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+var htmlTags = regexp.MustCompile(`<[^>]*>`)
+
+// escapeHTML escapes the only characters Telegram requires in HTML messages.
+func escapeHTML(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
+// plainText turns an HTML message into the text the user would read, for places without a parse mode.
+func plainText(text string) string {
+	return html.UnescapeString(htmlTags.ReplaceAllString(text, ""))
+}
+
+func isParseError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "can't parse entities")
+}
+
+// sendText sends an HTML message through the outbox, retrying it as plain text when Telegram cannot parse it.
+func sendText(msg tgbotapi.MessageConfig) (tgbotapi.Message, error) {
+	msg.ParseMode = tgbotapi.ModeHTML
+	sent, err := deliver(msg.ChatID, msg)
+	if isParseError(err) {
+		slog.Warn("could not parse a message, sending it as plain text", "chat_id", msg.ChatID, "error", err)
+		msg.ParseMode = ""
+		msg.Text = plainText(msg.Text)
+		sent, err = deliver(msg.ChatID, msg)
+	}
+	return sent, err
+}
+
+// editText replaces the text of a message, with the same plain text fallback as sendText.
+func editText(edit tgbotapi.EditMessageTextConfig) error {
+	edit.ParseMode = tgbotapi.ModeHTML
+	_, err := deliver(edit.ChatID, edit)
+	if isParseError(err) {
+		slog.Warn("could not parse an edited message, sending it as plain text", "chat_id", edit.ChatID, "message_id", edit.MessageID, "error", err)
+		edit.ParseMode = ""
+		edit.Text = plainText(edit.Text)
+		_, err = deliver(edit.ChatID, edit)
+	}
+	return err
+}
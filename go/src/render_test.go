@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func TestPlainTextFallback(t *testing.T) {
+	chat := newTestGroup(t)
+	fake.setRejectHTML(chat.chat.ID, true)
+	defer fake.setRejectHTML(chat.chat.ID, false)
+
+	sent, err := sendText(tgbotapi.NewMessage(chat.chat.ID, "<b>Partido 1</b> en Av. 9 &amp; 10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resent := chat.expect()
+	if resent.Method != "sendMessage" || resent.text() != "Partido 1 en Av. 9 & 10" || resent.Params.Get("parse_mode") != "" {
+		t.Errorf("expected the message to be sent again as plain text, got %s %q in %q", resent.Method, resent.text(), resent.Params.Get("parse_mode"))
+	}
+
+	if err := editText(tgbotapi.NewEditMessageText(chat.chat.ID, sent.MessageID, "<i>Cancelado</i> &lt;3")); err != nil {
+		t.Fatal(err)
+	}
+	edited := chat.expect()
+	if edited.Method != "editMessageText" || edited.text() != "Cancelado <3" || edited.Params.Get("parse_mode") != "" {
+		t.Errorf("expected the edit to be sent again as plain text, got %s %q in %q", edited.Method, edited.text(), edited.Params.Get("parse_mode"))
+	}
+
+	for _, method := range []string{"sendMessage", "editMessageText"} {
+		tried := false
+		for _, call := range fake.calls(method) {
+			tried = tried || call.chatID() == chat.chat.ID && call.Params.Get("parse_mode") == tgbotapi.ModeHTML
+		}
+		if !tried {
+			t.Errorf("expected %s to be tried in HTML first", method)
+		}
+	}
+}
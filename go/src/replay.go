@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// `fulbot replay [-config fulbot.json] recording.jsonl` feeds the updates of a recording through the dispatcher, on a
+// fresh store, with the settings of the recorded bot and the clock set to the recorded times, and compares what the
+// bot says now with what it said then.
+
+// replayedMethods are the calls compared between the recording and the replay.
+var replayedMethods = map[string]bool{
+	"sendMessage":         true,
+	"editMessageText":     true,
+	"sendVenue":           true,
+	"answerCallbackQuery": true,
+}
+
+type memberKey struct {
+	chatID string
+	userID string
+}
+
+// replayTransport answers the Bot API in memory during a replay. Sent messages get the IDs they had in the recording,
+// so buttons pressed in recorded updates still point at them, and chat members are answered as they were recorded.
+type replayTransport struct {
+	mutex         sync.Mutex
+	messageIDs    []int
+	members       map[memberKey]tgbotapi.ChatMember
+	sent          []RecordEntry
+	nextMessageID int
+}
+
+func newReplayTransport(entries []RecordEntry) *replayTransport {
+	transport := &replayTransport{members: make(map[memberKey]tgbotapi.ChatMember), nextMessageID: 1}
+	for _, entry := range entries {
+		switch {
+		case entry.Member != nil:
+			transport.members[memberKey{entry.Params.Get("chat_id"), entry.Params.Get("user_id")}] = *entry.Member
+		case entry.Method == "sendMessage" || entry.Method == "sendVenue":
+			transport.messageIDs = append(transport.messageIDs, entry.MessageID)
+		}
+		if entry.MessageID >= transport.nextMessageID {
+			transport.nextMessageID = entry.MessageID + 1
+		}
+		if entry.Update != nil && entry.Update.Message != nil && entry.Update.Message.MessageID >= transport.nextMessageID {
+			transport.nextMessageID = entry.Update.Message.MessageID + 1
+		}
+	}
+	return transport
+}
+
+func (transport *replayTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	method := path.Base(request.URL.Path)
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	params, _ := url.ParseQuery(string(body))
+	chatID, _ := strconv.ParseInt(params.Get("chat_id"), 10, 64)
+
+	transport.mutex.Lock()
+	var result interface{} = true
+	switch method {
+	case "getMe":
+		result = tgbotapi.User{UserName: "fulbot_replay", IsBot: true}
+	case "getChatMember":
+		member, exists := transport.members[memberKey{params.Get("chat_id"), params.Get("user_id")}]
+		if !exists {
+			userID, _ := strconv.Atoi(params.Get("user_id"))
+			member = tgbotapi.ChatMember{User: &tgbotapi.User{ID: userID}, Status: "member"}
+		}
+		result = member
+	case "sendMessage", "sendVenue", "editMessageText":
+		messageID, _ := strconv.Atoi(params.Get("message_id"))
+		if messageID == 0 {
+			messageID = transport.sentMessageID()
+		}
+		transport.sent = append(transport.sent, RecordEntry{Method: method, Params: params, MessageID: messageID})
+		result = tgbotapi.Message{MessageID: messageID, Chat: &tgbotapi.Chat{ID: chatID}, Text: params.Get("text")}
+	case "answerCallbackQuery":
+		transport.sent = append(transport.sent, RecordEntry{Method: method, Params: params})
+	}
+	transport.mutex.Unlock()
+
+	raw, _ := json.Marshal(result)
+	answer, _ := json.Marshal(tgbotapi.APIResponse{Ok: true, Result: raw})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(answer)),
+		Request:    request,
+	}, nil
+}
+
+// sentMessageID returns the ID the next sent message had in the recording, or a new one past the recorded ones.
+func (transport *replayTransport) sentMessageID() int {
+	if len(transport.messageIDs) > 0 {
+		id := transport.messageIDs[0]
+		transport.messageIDs = transport.messageIDs[1:]
+		return id
+	}
+	transport.nextMessageID++
+	return transport.nextMessageID - 1
+}
+
+// resetStore forgets every game, chat, conversation and user, like a bot that just started.
+func resetStore() {
+	mutex.Lock()
+	games = make(map[int]Game)
+	nextGameId = 1
+	mutex.Unlock()
+
+	chatsMutex.Lock()
+	chats = make(map[int64]ChatSettings)
+	chatTitles = make(map[int64]string)
+	chatsMutex.Unlock()
+
+	conversationsMutex.Lock()
+	conversations = make(map[conversationKey]*Conversation)
+	conversationsMutex.Unlock()
+
+	usersMutex.Lock()
+	knownUsers = make(map[int]knownUser)
+	usersMutex.Unlock()
+}
+
+// replay runs the updates of a recording on a fresh store and returns the calls the bot made. The scheduler runs on the recorded
+// clock between updates, so priority windows and polls close as they did.
+func replay(entries []RecordEntry) ([]RecordEntry, error) {
+	transport := newReplayTransport(entries)
+	client, err := tgbotapi.NewBotAPIWithClient("replay", &http.Client{Transport: transport})
+	if err != nil {
+		return nil, err
+	}
+
+	resetStore()
+	previousBot, previousClock := bot, clock
+	defer func() { bot, clock = previousBot, previousClock }()
+	bot = client
+	var now time.Time
+	clock = func() time.Time { return now }
+
+	var lastRun time.Time
+	for _, entry := range entries {
+		if entry.Update == nil {
+			continue
+		}
+		if lastRun.IsZero() {
+			lastRun = entry.Time
+		}
+		for !lastRun.Add(schedulerInterval).After(entry.Time) {
+			lastRun = lastRun.Add(schedulerInterval)
+			now = lastRun
+			runScheduledJobs(now)
+		}
+		now = entry.Time
+		handleUpdate(*entry.Update)
+		handlers.Wait()
+	}
+
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	return transport.sent, nil
+}
+
+// describeCall shows a call in a diff.
+func describeCall(entry RecordEntry) string {
+	if entry.Method == "answerCallbackQuery" {
+		return fmt.Sprintf("%s %q", entry.Method, entry.Params.Get("text"))
+	}
+	description := fmt.Sprintf("%s to %s", entry.Method, entry.Params.Get("chat_id"))
+	if entry.Method == "sendVenue" {
+		return description + fmt.Sprintf(" %q %q", entry.Params.Get("title"), entry.Params.Get("address"))
+	}
+	description += fmt.Sprintf(" %q", entry.Params.Get("text"))
+	if markup := entry.Params.Get("reply_markup"); markup != "" {
+		description += " " + markup
+	}
+	return description
+}
+
+// diffCalls compares the recorded calls with the replayed ones, one line per call, and returns the differences.
+func diffCalls(recorded []RecordEntry, replayed []RecordEntry) []string {
+	differences := make([]string, 0)
+	for i := 0; i < len(recorded) || i < len(replayed); i++ {
+		var before, after string
+		if i < len(recorded) {
+			before = describeCall(recorded[i])
+		}
+		if i < len(replayed) {
+			after = describeCall(replayed[i])
+		}
+		if before == after {
+			continue
+		}
+		difference := fmt.Sprintf("@@ call %d @@", i+1)
+		if before != "" {
+			difference += "\n- " + before
+		}
+		if after != "" {
+			difference += "\n+ " + after
+		}
+		differences = append(differences, difference)
+	}
+	return differences
+}
+
+// replayCommand runs `fulbot replay`, returning the exit code: 0 when the replay matches the recording. The settings
+// of the recorded bot are read like the bot reads them, from the file, the environment and the flags before the
+// recording, since its language, admins or templates change what it answers. The token is not needed.
+func replayCommand(args []string) int {
+	if len(args) < 1 || strings.HasPrefix(args[len(args)-1], "-") {
+		fmt.Fprintln(os.Stderr, "Usage: fulbot replay [-config fulbot.json] [settings] recording.jsonl")
+		return 2
+	}
+	getenv := func(name string) string {
+		if value := os.Getenv(name); value != "" || name != "FULBOT_TOKEN" {
+			return value
+		}
+		return "replay"
+	}
+	config, err := loadConfig(args[:len(args)-1], getenv)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error loading the configuration:", err)
+		return 2
+	}
+	entries, err := readRecording(args[len(args)-1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading the recording:", err)
+		return 2
+	}
+
+	config.RateLimits = RateLimitConfig{}
+	applyConfig(config)
+	setConfig(config)
+
+	replayed, err := replay(entries)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error replaying:", err)
+		return 2
+	}
+	recorded := make([]RecordEntry, 0)
+	updates := 0
+	for _, entry := range entries {
+		if entry.Update != nil {
+			updates++
+		} else if replayedMethods[entry.Method] {
+			recorded = append(recorded, entry)
+		}
+	}
+
+	differences := diffCalls(recorded, replayed)
+	for _, difference := range differences {
+		fmt.Println(difference)
+	}
+	fmt.Printf("Replayed %d updates: %d calls recorded, %d replayed, %d different.\n", updates, len(recorded), len(replayed), len(differences))
+	if len(differences) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// recordedCommand is an update with a command, as a recording stores it.
+func recordedCommand(at time.Time, chat *tgbotapi.Chat, messageID int, from tgbotapi.User, text string) RecordEntry {
+	command := text
+	for i, character := range text {
+		if character == ' ' {
+			command = text[:i]
+			break
+		}
+	}
+	message := &tgbotapi.Message{
+		MessageID: messageID,
+		From:      &from,
+		Chat:      chat,
+		Date:      int(at.Unix()),
+		Text:      text,
+		Entities:  &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}},
+	}
+	return RecordEntry{Time: at, Update: &tgbotapi.Update{UpdateID: messageID, Message: message}}
+}
+
+func writeRecording(t *testing.T, entries []RecordEntry) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "recording.jsonl")
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filename
+}
+
+func TestReplay(t *testing.T) {
+	chat := &tgbotapi.Chat{ID: -5000, Type: "group"}
+	start := time.Date(2024, 5, 21, 20, 0, 0, 0, time.Local)
+	admin := RecordEntry{Method: "getChatMember", Params: map[string][]string{"chat_id": {"-5000"}, "user_id": {strconv.Itoa(ana.ID)}},
+		Member: &tgbotapi.ChatMember{User: &ana, Status: "administrator"}}
+	updates := []RecordEntry{
+		admin,
+		recordedCommand(start, chat, 1, ana, "/socios agregar"),
+		recordedCommand(start.Add(time.Minute), chat, 2, ana, "/socios ventana 30"),
+		recordedCommand(start.Add(2*time.Minute), chat, 3, ana, "/nuevopartido 5"),
+		recordedCommand(start.Add(3*time.Minute), chat, 4, carla, "/yojuego 1"),
+		recordedCommand(start.Add(40*time.Minute), chat, 5, bruno, "/verpartido 1"),
+	}
+
+	replayed, err := replay(updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != 6 {
+		t.Fatalf("expected 6 messages, got %d", len(replayed))
+	}
+	assertContains(t, replayed[2].Params.Get("text"), "Los socios tienen prioridad hasta las 20:32")
+	assertContains(t, replayed[3].Params.Get("text"), "@Carla los socios tienen prioridad hasta las 20:32")
+	assertContains(t, replayed[4].Params.Get("text"), "Termino la prioridad para socios del partido 1.", "Se sumaron: Carla Paz.")
+	assertContains(t, replayed[5].Params.Get("text"), "1. Carla Paz", "Total de jugadores: 1/10")
+
+	recording := writeRecording(t, append(updates, replayed...))
+	if code := replayCommand([]string{recording}); code != 0 {
+		t.Errorf("expected the replay to match its own recording, got exit code %d", code)
+	}
+
+	replayed[5].Params.Set("text", "Partido 1: lleno")
+	if differences := diffCalls(replayed, replayed[:5]); len(differences) != 1 {
+		t.Errorf("expected a missing call to be reported, got %v", differences)
+	}
+	recording = writeRecording(t, append(updates, replayed...))
+	if code := replayCommand([]string{recording}); code != 1 {
+		t.Errorf("expected the replay to differ from an edited recording, got exit code %d", code)
+	}
+}
+
+func TestReplayKeepsRecordedMessageIDs(t *testing.T) {
+	chat := &tgbotapi.Chat{ID: -5001, Type: "group"}
+	start := time.Date(2024, 5, 21, 20, 0, 0, 0, time.Local)
+	wizard := recordedCommand(start, chat, 10, ana, "/nuevopartido")
+	prompt := RecordEntry{Method: "sendMessage", Params: map[string][]string{"chat_id": {strconv.FormatInt(chat.ID, 10)}}, MessageID: 77}
+	press := RecordEntry{Time: start.Add(time.Minute), Update: &tgbotapi.Update{UpdateID: 11, CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "1",
+		From:    &ana,
+		Message: &tgbotapi.Message{MessageID: 77, Chat: chat},
+		Data:    "wizard:o:0",
+	}}}
+
+	replayed, err := replay([]RecordEntry{wizard, prompt, press})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != 3 || replayed[0].MessageID != 77 {
+		t.Fatalf("expected the prompt to keep its recorded ID, got %+v", replayed)
+	}
+	assertContains(t, replayed[2].Params.Get("text"), "¿que dia se juega?")
+}
+
+func TestRecordingTransport(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "recording.jsonl")
+	if err := startRecording(filename); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		activeRecorder.file.Close()
+		activeRecorder = nil
+	}()
+
+	target, _ := url.Parse(fake.server.URL)
+	client, err := tgbotapi.NewBotAPIWithClient(fakeToken, &http.Client{Transport: recordingTransport{next: redirectTransport{target: target}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent, err := client.Send(tgbotapi.NewMessage(-5002, "hola"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.next(t)
+	if _, err := client.GetChatMember(tgbotapi.ChatConfigWithUser{ChatID: -5002, UserID: ana.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := readRecording(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	if entries[0].Method != "sendMessage" || entries[0].Params.Get("text") != "hola" || entries[0].MessageID != sent.MessageID {
+		t.Errorf("unexpected message entry %+v", entries[0])
+	}
+	if entries[1].Member == nil || entries[1].Member.User.ID != ana.ID {
+		t.Errorf("unexpected member entry %+v", entries[1])
+	}
+}
+
+func TestReplayWithRecordedConfig(t *testing.T) {
+	previous := getConfig()
+	defer func() { applyConfig(previous); setConfig(previous) }()
+
+	chat := &tgbotapi.Chat{ID: -5003, Type: "group"}
+	start := time.Date(2024, 5, 21, 20, 0, 0, 0, time.Local)
+	updates := []RecordEntry{recordedCommand(start, chat, 1, ana, "/newgame 5")}
+	config := *previous
+	config.Language = "en"
+	applyConfig(&config)
+	setConfig(&config)
+	replayed, err := replay(updates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, replayed[0].Params.Get("text"), "A new 5 a side game")
+	recording := writeRecording(t, append(updates, replayed...))
+
+	configFile := filepath.Join(t.TempDir(), "fulbot.json")
+	if err := os.WriteFile(configFile, []byte(`{"language": "en"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if code := replayCommand([]string{"-config", configFile, recording}); code != 0 {
+		t.Errorf("expected the replay with the recorded settings to match, got exit code %d", code)
+	}
+	if code := replayCommand([]string{"-language", "es", recording}); code != 1 {
+		t.Errorf("expected the replay in another language to differ, got exit code %d", code)
+	}
+	if code := replayCommand([]string{"-config", filepath.Join(t.TempDir(), "missing.json"), recording}); code != 2 {
+		t.Errorf("expected a missing configuration file to be an error, got exit code %d", code)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"time"
+)
+
+var schedulerInterval = 30 * time.Second
+
+// clock tells the time in the configured timezone to everything that depends on it, so a replay can run on the
+// recorded times.
+var clock = func() time.Time {
+	return time.Now().In(timezone())
+}
+
+// scheduledJobs run periodically from the scheduler, each one receiving the current time.
+var scheduledJobs = []func(now time.Time){
+	closePriorityWindows,
+	instantiateRecurringGames,
+	closeExpiredDatePolls,
+	expireConversations,
+	takeDueBackup,
+}
+
+func runScheduler() {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		runScheduledJobs(clock())
+	}
+}
+
+func runScheduledJobs(now time.Time) {
+	for _, job := range scheduledJobs {
+		job(now)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// TelegramClient is the part of the Bot API the bot uses. *tgbotapi.BotAPI implements it, and the tests point one at a
+// fake Telegram server so that no real token is needed.
+type TelegramClient interface {
+	Send(chattable tgbotapi.Chattable) (tgbotapi.Message, error)
+	AnswerCallbackQuery(config tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error)
+	GetChatMember(config tgbotapi.ChatConfigWithUser) (tgbotapi.ChatMember, error)
+	MakeRequest(endpoint string, params url.Values) (tgbotapi.APIResponse, error)
+	GetUpdatesChan(config tgbotapi.UpdateConfig) (tgbotapi.UpdatesChannel, error)
+	SetWebhook(config tgbotapi.WebhookConfig) (tgbotapi.APIResponse, error)
+	RemoveWebhook() (tgbotapi.APIResponse, error)
+	ListenForWebhook(pattern string) tgbotapi.UpdatesChannel
+}
+
+// connect logs in to Telegram with the bot token.
+func connect(token string) (TelegramClient, error) {
+	var transport http.RoundTripper = metricsTransport{next: loggingTransport{next: http.DefaultTransport}}
+	if activeRecorder != nil {
+		transport = recordingTransport{next: transport}
+	}
+	client := &http.Client{Transport: transport}
+	api, err := tgbotapi.NewBotAPIWithClient(token, client)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("connected to Telegram", "bot", api.Self.UserName)
+	return api, nil
+}
+
+// openUpdates receives updates through the webhook when there is one, or by polling Telegram otherwise.
+func openUpdates(bot TelegramClient, webhook WebhookConfig) (tgbotapi.UpdatesChannel, error) {
+	if webhook.URL == "" {
+		if _, err := bot.RemoveWebhook(); err != nil {
+			return nil, err
+		}
+		u := tgbotapi.NewUpdate(0)
+		u.Timeout = 60
+		return bot.GetUpdatesChan(u)
+	}
+
+	if _, err := bot.SetWebhook(tgbotapi.NewWebhook(webhook.URL)); err != nil {
+		return nil, err
+	}
+	pattern := "/"
+	if hook, err := url.Parse(webhook.URL); err == nil && hook.Path != "" {
+		pattern = hook.Path
+	}
+	updates := bot.ListenForWebhook(pattern)
+	go func() {
+		checkForFatalError("error serving the webhook", http.ListenAndServe(webhook.Listen, nil))
+	}()
+	slog.Info("receiving updates through the webhook", "listen", webhook.Listen, "path", pattern)
+	return updates, nil
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Announcements are rendered with text/template so every chat can write them in its own tone with /plantilla.
+// Templates produce HTML like the rest of the messages, and every text field they get is already escaped.
+
+// TemplateData is what a message template can use.
+type TemplateData struct {
+	// Game is the game the message is about, empty in the list template.
+	Game TemplateGame
+	// Games are the pending games, only in the list template.
+	Games []TemplateGame
+	// User is the first name of whoever sent the command.
+	User HTML
+}
+
+// TemplateGame describes a game for templates. Organizer, Players, Guests, Waitlist and Debtors need to look up
+// each person in Telegram, so they are only filled in the roster template.
+type TemplateGame struct {
+	Id       int
+	Size     HTML
+	Date     HTML
+	Schedule HTML
+	Address  HTML
+	// Venue is the name of the saved venue the address comes from, if any.
+	Venue HTML
+	// Count is the number of players plus guests.
+	Count      int
+	MaxPlayers int
+	FreeSpots  int
+	// PriorityUntil is the time the members priority window ends, empty when there is none.
+	PriorityUntil string
+	// Cost and Share are the total cost and what each player pays, empty when no cost was set.
+	Cost      HTML
+	Share     HTML
+	Organizer HTML
+	Players   []TemplatePlayer
+	Guests    []TemplatePlayer
+	Waitlist  []HTML
+	Debtors   []TemplateDebtor
+}
+
+type TemplatePlayer struct {
+	Name HTML
+	// InvitedBy is who invited a guest, empty for players.
+	InvitedBy HTML
+}
+
+type TemplateDebtor struct {
+	Name   HTML
+	Amount HTML
+}
+
+const (
+	templateRoster   = "roster"
+	templateList     = "list"
+	templateCreation = "creation"
+	templateReminder = "reminder"
+)
+
+// templateNames are the templates a chat can override, in the order /plantilla lists them.
+var templateNames = []string{templateRoster, templateList, templateCreation, templateReminder}
+
+// templateOptions are the message keys of the names users call each template by.
+var templateOptions = map[string]string{
+	templateRoster:   "option.template_roster",
+	templateList:     "option.template_list",
+	templateCreation: "option.template_creation",
+	templateReminder: "option.template_reminder",
+}
+
+// templateFields documents the data model in /plantilla.
+var templateFields = strings.Join([]string{
+	".Game.Id", ".Game.Size", ".Game.Date", ".Game.Schedule", ".Game.Address", ".Game.Venue",
+	".Game.Count", ".Game.MaxPlayers", ".Game.FreeSpots", ".Game.PriorityUntil", ".Game.Cost", ".Game.Share",
+	".Game.Organizer", ".Game.Players (.Name)", ".Game.Guests (.Name .InvitedBy)", ".Game.Waitlist",
+	".Game.Debtors (.Name .Amount)", ".Games", ".User",
+}, ", ")
+
+var maxTemplateLength = 4096
+
+var errEmptyTemplate = errors.New("the template renders an empty message")
+var errLongTemplate = errors.New("the template renders a message longer than Telegram allows")
+
+// detailsTemplate can be included by any template with {{template "detalles" .Game}}.
+var detailsTemplate = `
+{{- with .Date}}
+    - {{t "game.date" "date" .}}{{end}}
+{{- with .Schedule}}
+    - {{t "game.schedule" "schedule" .}}{{end}}
+{{- with .Address}}
+    - {{t "game.address" "address" .}}{{with $.Venue}} ({{.}}){{end}}{{end}}`
+
+var defaultTemplates = map[string]string{
+	templateRoster: `{{t "game.title" "game" .Game.Id}}
+{{template "detalles" .Game}}
+{{t "game.players"}}
+{{$players := .Game.Players}}{{range $i, $player := $players}}{{add $i 1}}. {{$player.Name}}
+{{end}}{{range $i, $guest := .Game.Guests}}{{add $i (len $players) 1}}. {{t "guest.invited_by" "guest" $guest.Name "inviter" $guest.InvitedBy}}
+{{end}}
+{{t "game.total" "count" .Game.Count "max" .Game.MaxPlayers}}
+{{- with .Game.Waitlist}}
+
+{{t "game.waitlist" "time" $.Game.PriorityUntil}}
+{{range $i, $entry := .}}{{add $i 1}}. {{$entry}}
+{{end}}{{end}}
+{{- if .Game.Cost}}
+
+` + emojiMoney + ` {{t "game.cost" "cost" .Game.Cost "share" .Game.Share}}
+{{with .Game.Debtors}}{{t "game.unpaid"}}
+{{range .}}` + unicodeBulletPoint + ` {{.Name}}: {{.Amount}}
+{{end}}{{else}}{{t "game.all_paid"}}{{end}}{{end}}`,
+
+	templateList: `{{if not .Games}}{{t "games.none" "name" .User}}{{else}}{{t "games.title"}}
+
+{{range .Games}}` + unicodeBulletPoint + ` {{t "games.item" "game" .Id "players" .Count "max" .MaxPlayers}}{{template "detalles" .}}
+{{end}}{{plural "games.total" (len .Games)}}
+
+{{t "games.more"}}{{end}}`,
+
+	templateCreation: emojiBall + ` {{t "newgame.created" "size" .Game.Size "game" .Game.Id}}{{template "detalles" .Game}}
+{{- with .Game.PriorityUntil}}
+
+{{t "newgame.priority" "time" .}}{{end}}`,
+
+	templateReminder: emojiCalendar + ` {{t "recurring.created" "date" .Game.Date "game" .Game.Id}}
+{{- with .Game.Count}} {{plural "recurring.regulars_added" .}}{{end}} {{t "recurring.join" "game" .Game.Id}}`,
+}
+
+func templateFuncs(language string) template.FuncMap {
+	return template.FuncMap{
+		"t": func(key string, params ...interface{}) string {
+			return tr(language, key, params...)
+		},
+		"plural": func(key string, count int, params ...interface{}) string {
+			return trn(language, key, count, params...)
+		},
+		"add": func(numbers ...int) int {
+			total := 0
+			for _, number := range numbers {
+				total += number
+			}
+			return total
+		},
+	}
+}
+
+func parseTemplate(language string, source string) (*template.Template, error) {
+	parsed := template.New("mensaje").Funcs(templateFuncs(language))
+	if _, err := parsed.New("detalles").Parse(detailsTemplate); err != nil {
+		return nil, err
+	}
+	return parsed.Parse(source)
+}
+
+// limitedBuilder stops a template as soon as it writes more than maxTemplateLength, so a loop like {{range 1000000000}}
+// fails early instead of taking all the memory of the bot.
+type limitedBuilder struct {
+	strings.Builder
+}
+
+func (builder *limitedBuilder) Write(text []byte) (int, error) {
+	if builder.Len()+len(text) > maxTemplateLength {
+		return 0, errLongTemplate
+	}
+	return builder.Builder.Write(text)
+}
+
+func executeTemplate(language string, source string, data TemplateData) (string, error) {
+	parsed, err := parseTemplate(language, source)
+	if err != nil {
+		return "", err
+	}
+	var text limitedBuilder
+	if err := parsed.Execute(&text, data); err != nil {
+		if errors.Is(err, errLongTemplate) {
+			return "", errLongTemplate
+		}
+		return "", err
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return "", errEmptyTemplate
+	}
+	return text.String(), nil
+}
+
+// renderTemplate renders a message with the template of the chat, falling back to the default one if it fails.
+func renderTemplate(chatID int64, name string, data TemplateData) string {
+	templates := getChatSettings(chatID).Templates
+	if _, custom := templates[name]; !custom {
+		if configured, exists := getConfig().Templates[name]; exists {
+			templates = map[string]string{name: configured}
+		}
+	}
+	return renderMessage(chatLanguage(chatID), templates, name, data)
+}
+
+// baseTemplate is the template a chat uses when it did not write its own: the configured one or the default.
+func baseTemplate(name string) string {
+	if configured, exists := getConfig().Templates[name]; exists {
+		return configured
+	}
+	return defaultTemplates[name]
+}
+
+// renderMessage renders a message with the custom template among templates, or the default one if there is none or
+// it fails. It only depends on its arguments, so tests can check every message without a bot.
+func renderMessage(language string, templates map[string]string, name string, data TemplateData) string {
+	if custom, exists := templates[name]; exists {
+		text, err := executeTemplate(language, custom, data)
+		if err == nil {
+			return text
+		}
+		slog.Warn("error rendering a custom template, using the default one", "template", name, "error", err)
+	}
+	text, err := executeTemplate(language, defaultTemplates[name], data)
+	if err != nil {
+		slog.Error("error rendering a default template", "template", name, "error", err)
+	}
+	return text
+}
+
+// PlayerNames tells how to show the people in a game. The bot looks them up in Telegram, tests use fixed names.
+type PlayerNames struct {
+	// Name is the full name of a player.
+	Name func(userID int) string
+	// Handle is the @username of a player, or their name when they have none.
+	Handle func(userID int) string
+}
+
+func chatPlayerNames(chatID int64) PlayerNames {
+	return PlayerNames{
+		Name:   func(userID int) string { return getPlayerName(bot, chatID, userID) },
+		Handle: func(userID int) string { return getPlayerHandle(bot, chatID, userID) },
+	}
+}
+
+// gameTemplateData describes a game, looking up the people in it only when detailed.
+func gameTemplateData(game Game, detailed bool) TemplateGame {
+	language := chatLanguage(game.ChatID)
+	if !detailed {
+		return gameView(language, game, nil)
+	}
+	names := chatPlayerNames(game.ChatID)
+	return gameView(language, game, &names)
+}
+
+// gameView describes a game for templates, including the people in it when names are given.
+func gameView(language string, game Game, names *PlayerNames) TemplateGame {
+	data := TemplateGame{
+		Id:         game.Id,
+		Size:       HTML(escapeHTML(game.Size)),
+		Date:       HTML(escapeHTML(strings.Join(game.Date, " "))),
+		Schedule:   HTML(escapeHTML(strings.Join(game.Schedule, " "))),
+		Address:    HTML(escapeHTML(strings.Join(game.Address, " "))),
+		Venue:      HTML(escapeHTML(game.Venue)),
+		Count:      game.headcount(),
+		MaxPlayers: game.MaxPlayers,
+	}
+	if data.MaxPlayers > data.Count {
+		data.FreeSpots = data.MaxPlayers - data.Count
+	}
+	if !game.PriorityUntil.IsZero() {
+		data.PriorityUntil = game.PriorityUntil.Format("15:04")
+	}
+	if game.Cost > 0 {
+		data.Cost = HTML(escapeHTML(formatAmount(game.Cost)))
+		data.Share = HTML(escapeHTML(formatAmount(game.share())))
+	}
+	if names == nil {
+		return data
+	}
+
+	data.Organizer = HTML(escapeHTML(names.Name(game.OrganizerID)))
+	for _, playerID := range game.Players {
+		data.Players = append(data.Players, TemplatePlayer{Name: HTML(escapeHTML(names.Name(playerID)))})
+	}
+	for _, guest := range game.Guests {
+		data.Guests = append(data.Guests, TemplatePlayer{
+			Name:      HTML(escapeHTML(guest.Name)),
+			InvitedBy: HTML(escapeHTML(names.Handle(guest.InviterID))),
+		})
+	}
+	for _, entry := range game.Waitlist {
+		data.Waitlist = append(data.Waitlist, HTML(waitlistNames(language, []WaitlistEntry{entry}, *names)))
+	}
+	if game.Cost > 0 {
+		for _, playerID := range game.debtors() {
+			data.Debtors = append(data.Debtors, TemplateDebtor{
+				Name:   HTML(escapeHTML(names.Name(playerID))),
+				Amount: HTML(escapeHTML(formatAmount(game.amountOwedBy(playerID)))),
+			})
+		}
+	}
+	return data
+}
+
+// pendingGamesTemplateData lists the active games by number.
+func pendingGamesTemplateData(language string) []TemplateGame {
+	mutex.Lock()
+	all := make([]Game, 0, len(games))
+	for _, game := range games {
+		all = append(all, game)
+	}
+	mutex.Unlock()
+	return gameListView(language, all)
+}
+
+// gameListView describes the active games among all, sorted by number.
+func gameListView(language string, all []Game) []TemplateGame {
+	pending := make([]Game, 0, len(all))
+	for _, game := range all {
+		if game.Active {
+			pending = append(pending, game)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Id < pending[j].Id })
+	data := make([]TemplateGame, 0, len(pending))
+	for _, game := range pending {
+		data = append(data, gameView(language, game, nil))
+	}
+	return data
+}
+
+// previewTemplateData is a made up game to show how a template looks before saving it.
+func previewTemplateData(language string, user *tgbotapi.User) TemplateData {
+	game := TemplateGame{
+		Id:            7,
+		Size:          "5",
+		Date:          HTML(weekdayName(language, time.Tuesday) + " 21/05"),
+		Schedule:      "21:00",
+		Address:       "Av. Siempre Viva 742",
+		Venue:         "La Canchita",
+		Count:         3,
+		MaxPlayers:    10,
+		FreeSpots:     7,
+		Cost:          "$10000",
+		Share:         "$3334",
+		Organizer:     "Diego",
+		Players:       []TemplatePlayer{{Name: "Diego"}, {Name: "Lionel"}},
+		Guests:        []TemplatePlayer{{Name: "Juan", InvitedBy: "Diego"}},
+		Debtors:       []TemplateDebtor{{Name: "Lionel", Amount: "$3334"}},
+		PriorityUntil: "",
+	}
+	return TemplateData{Game: game, Games: []TemplateGame{game}, User: HTML(escapeHTML(user.FirstName))}
+}
+
+func findTemplateName(language string, text string) (string, bool) {
+	for _, name := range templateNames {
+		if isOption(language, text, templateOptions[name]) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// templateSource returns what follows the template name in the message, keeping its line breaks.
+func templateSource(message *tgbotapi.Message) string {
+	arguments := strings.TrimSpace(message.CommandArguments())
+	if index := strings.IndexAny(arguments, " \n\t"); index != -1 {
+		return strings.TrimSpace(arguments[index:])
+	}
+	return ""
+}
+
+func handlePlantillaCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	chat := getChatSettings(message.Chat.ID)
+	language := args.Language
+	var response string
+
+	name, found := findTemplateName(language, args.Text("nombre"))
+	source := templateSource(message)
+
+	switch {
+	case !args.Has("nombre"):
+		response = tr(language, "templates.list") + "\n"
+		for _, name := range templateNames {
+			response += unicodeBulletPoint + " " + tr(language, templateOptions[name])
+			if _, custom := chat.Templates[name]; custom {
+				response += " " + tr(language, "templates.custom")
+			}
+			response += "\n"
+		}
+		response += "\n" + tr(language, "templates.fields", "fields", templateFields)
+	case !found:
+		response = tr(language, "templates.unknown", "template", args.Text("nombre"))
+	case source == "":
+		current, custom := chat.Templates[name]
+		if !custom {
+			current = baseTemplate(name)
+		}
+		preview, _ := executeTemplate(language, current, previewTemplateData(language, message.From))
+		response = tr(language, "templates.current", "template", args.Text("nombre")) + "\n<pre>" + escapeHTML(current) + "</pre>\n\n" + tr(language, "templates.preview") + "\n\n" + preview
+	case isOption(language, source, "option.reset"):
+		chat.Templates = withoutTemplate(chat.Templates, name)
+		updateChatSettings(chat)
+		response = tr(language, "templates.reset", "template", args.Text("nombre"))
+	default:
+		preview, err := executeTemplate(language, source, previewTemplateData(language, message.From))
+		if err != nil {
+			response = tr(language, "templates.invalid", "error", err.Error())
+			break
+		}
+		chat.Templates = withTemplate(chat.Templates, name, source)
+		updateChatSettings(chat)
+		response = tr(language, "templates.saved", "template", args.Text("nombre")) + "\n\n" + preview
+	}
+	respondToMessage(message, response)
+}
+
+// withTemplate copies the templates of a chat with one of them replaced, as the settings are shared between goroutines.
+func withTemplate(templates map[string]string, name string, source string) map[string]string {
+	updated := withoutTemplate(templates, name)
+	updated[name] = source
+	return updated
+}
+
+func withoutTemplate(templates map[string]string, name string) map[string]string {
+	updated := make(map[string]string, len(templates))
+	for key, value := range templates {
+		if key != name {
+			updated[key] = value
+		}
+	}
+	return updated
+}
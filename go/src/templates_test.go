@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the current output")
+
+// assertGolden compares a message with testdata/<name>.golden, so every change to what the bot says shows up in review.
+func assertGolden(t *testing.T, name string, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run go test -update to create it", err)
+	}
+	if string(want) != got {
+		t.Errorf("%s changed, run go test -update if it is intended.\nwant:\n%s\ngot:\n%s", path, want, got)
+	}
+}
+
+var goldenPlayers = map[int]tgbotapi.User{
+	1: {ID: 1, FirstName: "Diego", UserName: "diego10"},
+	2: {ID: 2, FirstName: "Lionel", LastName: "Messi"},
+	3: {ID: 3, FirstName: "Ana <la 9>"},
+	4: {ID: 4, FirstName: "Carla", UserName: "carlita"},
+}
+
+var goldenNames = PlayerNames{
+	Name: func(userID int) string {
+		player := goldenPlayers[userID]
+		return player.FirstName + map[bool]string{true: " " + player.LastName}[player.LastName != ""]
+	},
+	Handle: func(userID int) string {
+		if player := goldenPlayers[userID]; player.UserName != "" {
+			return "@" + player.UserName
+		}
+		return goldenPlayers[userID].FirstName
+	},
+}
+
+// goldenGames are the games the golden files show, from a bare one to one using every field.
+func goldenGames() map[string]Game {
+	return map[string]Game{
+		"empty": {Id: 3, Active: true, Size: "5", MaxPlayers: 10, OrganizerID: 1},
+		"full": {
+			Id:            7,
+			Active:        true,
+			Size:          "5",
+			MaxPlayers:    10,
+			OrganizerID:   1,
+			Date:          []string{"martes", "21/05"},
+			Schedule:      []string{"21:00"},
+			Address:       []string{"Av.", "Siempre", "Viva", "742"},
+			Venue:         "La Canchita",
+			Players:       []int{1, 2, 3},
+			Guests:        []Guest{{Name: "Juan & Pedro", InviterID: 1}, {Name: "Tito", InviterID: 2}},
+			Waitlist:      []WaitlistEntry{{PlayerID: 4}, {PlayerID: 4, GuestName: "Nico"}},
+			PriorityUntil: time.Date(2024, 5, 21, 20, 30, 0, 0, time.Local),
+			Cost:          10000,
+			Payments:      []Payment{{PlayerID: 1, Amount: 4000}},
+		},
+		"complete": {
+			Id:          9,
+			Active:      true,
+			Size:        "1",
+			MaxPlayers:  2,
+			OrganizerID: 2,
+			Players:     []int{2},
+			Guests:      []Guest{{Name: "Tito", InviterID: 2}},
+			Cost:        3000,
+			Payments:    []Payment{{PlayerID: 2, Amount: 3000}},
+		},
+	}
+}
+
+func TestRosterGolden(t *testing.T) {
+	for _, language := range languages {
+		for name, game := range goldenGames() {
+			data := TemplateData{Game: gameView(language, game, &goldenNames), User: "Diego"}
+			assertGolden(t, "roster_"+name+"."+language, renderMessage(language, nil, templateRoster, data))
+		}
+	}
+}
+
+func TestListGolden(t *testing.T) {
+	games := goldenGames()
+	all := []Game{games["full"], games["empty"], games["complete"], {Id: 5, Active: false, Size: "5", MaxPlayers: 10}}
+	for _, language := range languages {
+		data := TemplateData{Games: gameListView(language, all), User: "Diego"}
+		assertGolden(t, "list."+language, renderMessage(language, nil, templateList, data))
+
+		none := TemplateData{Games: gameListView(language, nil), User: "Diego"}
+		assertGolden(t, "list_none."+language, renderMessage(language, nil, templateList, none))
+	}
+}
+
+func TestCreationAndReminderGolden(t *testing.T) {
+	for _, language := range languages {
+		for name, game := range goldenGames() {
+			data := TemplateData{Game: gameView(language, game, nil), User: "Diego"}
+			assertGolden(t, "creation_"+name+"."+language, renderMessage(language, nil, templateCreation, data))
+			assertGolden(t, "reminder_"+name+"."+language, renderMessage(language, nil, templateReminder, data))
+		}
+	}
+}
+
+func TestCustomTemplateGolden(t *testing.T) {
+	data := TemplateData{Game: gameView("es", goldenGames()["full"], &goldenNames), User: "Diego"}
+	custom := map[string]string{templateRoster: `{{.Game.Count}}/{{.Game.MaxPlayers}} - {{range .Game.Players}}{{.Name}}, {{end}}{{plural "games.total" 1}}`}
+	assertGolden(t, "roster_custom.es", renderMessage("es", custom, templateRoster, data))
+
+	broken := map[string]string{templateRoster: `{{.Game.Missing}}`}
+	if got, want := renderMessage("es", broken, templateRoster, data), renderMessage("es", nil, templateRoster, data); got != want {
+		t.Errorf("expected a broken template to fall back to the default one, got:\n%s", got)
+	}
+}
+
+func TestRunawayTemplate(t *testing.T) {
+	data := TemplateData{Game: gameView("es", goldenGames()["full"], &goldenNames)}
+	// Rendered whole, this would be 10 GB.
+	if _, err := executeTemplate("es", `{{range 1000000000}}xxxxxxxxxx{{end}}`, data); err != errLongTemplate {
+		t.Errorf("expected a runaway template to stop at the length limit, got %v", err)
+	}
+	if text, err := executeTemplate("es", `{{range 409}}xxxxxxxxxx{{end}}`, data); err != nil || len(text) != 4090 {
+		t.Errorf("expected a template under the limit to render, got %d bytes, %v", len(text), err)
+	}
+}
+
+func TestMyGamesGolden(t *testing.T) {
+	games := goldenGames()
+	full, complete := games["full"], games["complete"]
+	full.ChatID = -1
+	complete.ChatID = -2
+	invited := Game{Id: 12, ChatID: -1, Active: true, Size: "5", MaxPlayers: 10, OrganizerID: 1, Players: []int{1},
+		Guests: []Guest{{Name: "Tito", InviterID: 2}}}
+	titles := map[int64]string{-1: "Futbol <martes>"}
+	lionel := goldenPlayers[2]
+	for _, language := range languages {
+		assertGolden(t, "mygames."+language, userGamesText(language, &lionel, []Game{full, complete, invited}, titles))
+		assertGolden(t, "mygames_none."+language, userGamesText(language, &lionel, nil, titles))
+	}
+}
+
+func TestHelpGolden(t *testing.T) {
+	for _, language := range languages {
+		assertGolden(t, "help_group."+language, helpText(getCommands(), commandMenuScopes["all_group_chats"], language))
+		assertGolden(t, "help_private."+language, helpText(getCommands(), commandMenuScopes["all_private_chats"], language))
+	}
+}
+
+func TestDatePollGolden(t *testing.T) {
+	poll := DatePoll{
+		Options:  []string{"martes 21:00", "jueves 20:00", "viernes"},
+		Votes:    map[int]int{1: 1, 2: 1, 3: 0},
+		Deadline: time.Date(2024, 5, 20, 18, 0, 0, 0, time.Local),
+	}
+	for _, language := range languages {
+		assertGolden(t, "poll."+language, poll.text(language, 7))
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Names are remembered from every update the bot receives, so showing a game does not need to ask Telegram for
+// each player. Entries older than userRefreshAge are still used, but refreshed in the background.
+
+var userRefreshAge = 24 * time.Hour
+
+type knownUser struct {
+	User      tgbotapi.User
+	UpdatedAt time.Time
+}
+
+var usersMutex sync.Mutex
+var knownUsers map[int]knownUser = make(map[int]knownUser)
+var refreshingUsers map[int]bool = make(map[int]bool)
+
+// rememberUser stores the current name and username of a user seen in an update.
+func rememberUser(user *tgbotapi.User) {
+	if user == nil {
+		return
+	}
+	usersMutex.Lock()
+	defer usersMutex.Unlock()
+	knownUsers[user.ID] = knownUser{User: *user, UpdatedAt: clock()}
+}
+
+// rememberUsers stores everyone an update mentions: its author, who they reply to and who they mention.
+func rememberUsers(update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		rememberUser(update.CallbackQuery.From)
+	}
+	message := update.Message
+	if message == nil {
+		return
+	}
+	rememberUser(message.From)
+	if message.ReplyToMessage != nil {
+		rememberUser(message.ReplyToMessage.From)
+	}
+	if message.Entities != nil {
+		for _, entity := range *message.Entities {
+			rememberUser(entity.User)
+		}
+	}
+	if message.NewChatMembers != nil {
+		for i := range *message.NewChatMembers {
+			rememberUser(&(*message.NewChatMembers)[i])
+		}
+	}
+}
+
+// fetchUser asks Telegram for a user and remembers the answer.
+func fetchUser(bot TelegramClient, chatID int64, userID int) *tgbotapi.User {
+	member, err := bot.GetChatMember(tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID})
+	if err != nil || member.User == nil {
+		slog.Error("error obtaining user info", "chat_id", chatID, "user_id", userID, "error", err)
+		return nil
+	}
+	rememberUser(member.User)
+	return member.User
+}
+
+// refreshUser updates a remembered user in the background, at most once at a time per user.
+func refreshUser(bot TelegramClient, chatID int64, userID int) {
+	usersMutex.Lock()
+	if refreshingUsers[userID] {
+		usersMutex.Unlock()
+		return
+	}
+	refreshingUsers[userID] = true
+	usersMutex.Unlock()
+
+	go func() {
+		fetchUser(bot, chatID, userID)
+		usersMutex.Lock()
+		delete(refreshingUsers, userID)
+		usersMutex.Unlock()
+	}()
+}
+
+// getUserInfo returns a user from the cache, only asking Telegram for users the bot has never seen.
+func getUserInfo(bot TelegramClient, chatID int64, userID int) *tgbotapi.User {
+	usersMutex.Lock()
+	known, exists := knownUsers[userID]
+	usersMutex.Unlock()
+
+	if !exists {
+		return fetchUser(bot, chatID, userID)
+	}
+	if clock().Sub(known.UpdatedAt) > userRefreshAge {
+		refreshUser(bot, chatID, userID)
+	}
+	return &known.User
+}
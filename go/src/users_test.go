@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// memberClient answers every getChatMember with the same user and fails the rest of the calls.
+type memberClient struct {
+	TelegramClient
+	user tgbotapi.User
+}
+
+func (client memberClient) GetChatMember(config tgbotapi.ChatConfigWithUser) (tgbotapi.ChatMember, error) {
+	return tgbotapi.ChatMember{User: &client.user, Status: "member"}, nil
+}
+
+func TestGetUserInfoAsksTheGivenClient(t *testing.T) {
+	client := memberClient{user: tgbotapi.User{ID: 9501, FirstName: "Lucia"}}
+	if user := getUserInfo(client, -9501, 9501); user == nil || user.FirstName != "Lucia" {
+		t.Errorf("expected the user from the given client, got %+v", user)
+	}
+}
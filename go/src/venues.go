@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Venue is a field the chat plays at often, saved so nobody has to type the address again.
+type Venue struct {
+	Name        string
+	Address     string
+	HasLocation bool
+	Latitude    float64
+	Longitude   float64
+	// Notes is free text like the surface, price or parking.
+	Notes string
+}
+
+func (chat ChatSettings) findVenue(name string) (int, bool) {
+	for i, venue := range chat.Venues {
+		if strings.EqualFold(venue.Name, name) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// applyVenue sets the address of the game, using the saved venue when the text is one of its names.
+func applyVenue(game *Game, address []string) {
+	chat := getChatSettings(game.ChatID)
+	if index, found := chat.findVenue(strings.Join(address, " ")); found {
+		venue := chat.Venues[index]
+		game.Venue = venue.Name
+		game.Address = strings.Fields(venue.Address)
+		return
+	}
+	game.Venue = ""
+	game.Address = address
+}
+
+// sendVenue shares the venue of a game as a native Telegram venue so it can be opened in a maps app.
+func sendVenue(game Game) {
+	if game.Venue == "" {
+		return
+	}
+	chat := getChatSettings(game.ChatID)
+	index, found := chat.findVenue(game.Venue)
+	if !found || !chat.Venues[index].HasLocation {
+		return
+	}
+	venue := chat.Venues[index]
+	deliver(game.ChatID, tgbotapi.NewVenue(game.ChatID, venue.Name, venue.Address, venue.Latitude, venue.Longitude))
+}
+
+func (venue Venue) describe() string {
+	description := escapeHTML(venue.Name + ": " + venue.Address)
+	if venue.HasLocation {
+		description += " " + emojiAddress
+	}
+	if venue.Notes != "" {
+		description += " (" + escapeHTML(venue.Notes) + ")"
+	}
+	return description
+}
+
+func handleAgregarCanchaCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	chat := getChatSettings(message.Chat.ID)
+	var response string
+
+	text := args.Text("direccion | notas")
+	venue := Venue{Name: args.Text("nombre")}
+	if parts := strings.SplitN(text, "|", 2); len(parts) == 2 {
+		text = parts[0]
+		venue.Notes = strings.TrimSpace(parts[1])
+	}
+	venue.Address = strings.TrimSpace(text)
+
+	if venue.Address == "" {
+		response = tr(args.Language, "venues.address_missing", "name", message.From.FirstName)
+	} else {
+		if reply := message.ReplyToMessage; reply != nil {
+			if reply.Venue != nil {
+				venue.HasLocation = true
+				venue.Latitude = reply.Venue.Location.Latitude
+				venue.Longitude = reply.Venue.Location.Longitude
+			} else if reply.Location != nil {
+				venue.HasLocation = true
+				venue.Latitude = reply.Location.Latitude
+				venue.Longitude = reply.Location.Longitude
+			}
+		}
+
+		if index, found := chat.findVenue(venue.Name); found {
+			chat.Venues[index] = venue
+			response = tr(args.Language, "venues.updated", "venue", venue.Name)
+		} else {
+			chat.Venues = append(chat.Venues, venue)
+			response = tr(args.Language, "venues.saved", "venue", venue.Name)
+		}
+		updateChatSettings(chat)
+	}
+	respondToMessage(message, response)
+}
+
+func handleCanchasCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	chat := getChatSettings(message.Chat.ID)
+	var response string
+
+	if len(chat.Venues) == 0 {
+		response = tr(args.Language, "venues.empty")
+	} else {
+		response = tr(args.Language, "venues.list") + "\n"
+		for _, venue := range chat.Venues {
+			response += unicodeBulletPoint + " " + venue.describe() + "\n"
+		}
+	}
+	respondToMessage(message, response)
+}
+
+func handleBorrarCanchaCommand(bot TelegramClient, message *tgbotapi.Message, args CommandArgs) {
+	chat := getChatSettings(message.Chat.ID)
+	var response string
+
+	name := args.Text("nombre")
+	if index, found := chat.findVenue(name); !found {
+		response = tr(args.Language, "venues.not_found", "venue", name)
+	} else {
+		chat.Venues = append(chat.Venues[:index], chat.Venues[index+1:]...)
+		updateChatSettings(chat)
+		response = tr(args.Language, "venues.removed", "venue", name)
+	}
+	respondToMessage(message, response)
+}
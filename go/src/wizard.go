@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	wizardStepSize = iota
+	wizardStepDate
+	wizardStepSchedule
+	wizardStepVenue
+)
+
+var wizardTimeout = 10 * time.Minute
+
+var wizardSchedules = []string{"19:00", "20:00", "21:00", "22:00"}
+
+// Conversation is a game being created step by step by a user in a chat.
+type Conversation struct {
+	ChatID    int64
+	User      *tgbotapi.User
+	Step      int
+	Size      string
+	Date      []string
+	Schedule  []string
+	Address   []string
+	// MessageID is the prompt of the current step, Options are the answers its buttons stand for.
+	MessageID int
+	Options   []string
+	UpdatedAt time.Time
+}
+
+type conversationKey struct {
+	ChatID int64
+	UserID int
+}
+
+var conversationsMutex sync.Mutex
+var conversations map[conversationKey]*Conversation = make(map[conversationKey]*Conversation)
+
+func startGameWizard(bot TelegramClient, message *tgbotapi.Message) {
+	conversation := &Conversation{
+		ChatID:    message.Chat.ID,
+		User:      message.From,
+		Step:      wizardStepSize,
+		UpdatedAt: clock(),
+	}
+	conversationsMutex.Lock()
+	conversations[conversationKey{message.Chat.ID, message.From.ID}] = conversation
+	conversationsMutex.Unlock()
+
+	sendWizardStep(bot, conversation)
+}
+
+func (conversation *Conversation) prompt(language string) (string, []string) {
+	name := conversation.User.FirstName
+	switch conversation.Step {
+	case wizardStepSize:
+		return tr(language, "wizard.size", "name", name), offeredGameSizes()
+	case wizardStepDate:
+		return tr(language, "wizard.date", "name", name), wizardDateSuggestions(language, clock())
+	case wizardStepSchedule:
+		return tr(language, "wizard.schedule", "name", name), wizardSchedules
+	default:
+		suggestions := make([]string, 0)
+		for _, venue := range getChatSettings(conversation.ChatID).Venues {
+			suggestions = append(suggestions, venue.Name)
+		}
+		return tr(language, "wizard.venue", "name", name), suggestions
+	}
+}
+
+// wizardDateSuggestions offers today and the next days of the week.
+func wizardDateSuggestions(language string, now time.Time) []string {
+	suggestions := make([]string, 0, 4)
+	for i := 0; i < 4; i++ {
+		day := now.AddDate(0, 0, i)
+		suggestions = append(suggestions, weekdayName(language, day.Weekday())+" "+day.Format("02/01"))
+	}
+	return suggestions
+}
+
+func sendWizardStep(bot TelegramClient, conversation *Conversation) {
+	language := chatLanguage(conversation.ChatID)
+	text, suggestions := conversation.prompt(language)
+	text += "\n" + tr(language, "wizard.hint")
+
+	// Buttons carry the position of their answer, since a venue name may not fit in the 64 bytes of callback data.
+	option := func(i int) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(suggestions[i], "wizard:o:"+strconv.Itoa(i))
+	}
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0)
+	for i := 0; i < len(suggestions); i += 2 {
+		row := tgbotapi.NewInlineKeyboardRow(option(i))
+		if i+1 < len(suggestions) {
+			row = append(row, option(i+1))
+		}
+		rows = append(rows, row)
+	}
+	controls := tgbotapi.NewInlineKeyboardRow()
+	if conversation.Step > wizardStepSize {
+		controls = append(controls, tgbotapi.NewInlineKeyboardButtonData("« "+tr(language, "wizard.back"), "wizard:back"))
+	}
+	if conversation.Step == wizardStepVenue {
+		controls = append(controls, tgbotapi.NewInlineKeyboardButtonData(tr(language, "wizard.skip"), "wizard:skip"))
+	}
+	controls = append(controls, tgbotapi.NewInlineKeyboardButtonData(emojiCross+" "+tr(language, "wizard.cancel"), "wizard:cancel"))
+	rows = append(rows, controls)
+
+	msg := tgbotapi.NewMessage(conversation.ChatID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	sent, err := sendText(msg)
+	if err == nil {
+		conversationsMutex.Lock()
+		conversation.MessageID = sent.MessageID
+		conversation.Options = suggestions
+		conversationsMutex.Unlock()
+	}
+}
+
+// advance applies the answer to the current step and returns an error message when it is not valid.
+func (conversation *Conversation) advance(language string, answer string) string {
+	words := strings.Fields(answer)
+	if len(words) == 0 {
+		return tr(language, "wizard.not_understood")
+	}
+	switch conversation.Step {
+	case wizardStepSize:
+		if _, err := getMaxPlayersByTamano(words[0]); err != nil {
+			return translateError(language, err)
+		}
+		conversation.Size = words[0]
+	case wizardStepDate:
+		conversation.Date = words
+	case wizardStepSchedule:
+		hour, minute, err := parseTimeOfDay(words[0])
+		if err != nil {
+			return tr(language, "time.invalid", "time", words[0])
+		}
+		conversation.Schedule = []string{fmt.Sprintf("%02d:%02d", hour, minute)}
+	case wizardStepVenue:
+		conversation.Address = words
+	}
+	conversation.Step++
+	return ""
+}
+
+// handleWizardAnswer moves the conversation forward, finishing it and creating the game after the last step.
+func handleWizardAnswer(bot TelegramClient, conversation *Conversation, answer string) {
+	language := chatLanguage(conversation.ChatID)
+	conversationsMutex.Lock()
+	problem := conversation.advance(language, answer)
+	conversation.UpdatedAt = clock()
+	finished := conversation.Step > wizardStepVenue
+	if finished {
+		delete(conversations, conversationKey{conversation.ChatID, conversation.User.ID})
+	}
+	conversationsMutex.Unlock()
+
+	if problem != "" {
+		sendMessage(conversation.ChatID, problem)
+		sendWizardStep(bot, conversation)
+	} else if finished {
+		finishGameWizard(bot, conversation)
+	} else {
+		sendWizardStep(bot, conversation)
+	}
+}
+
+func finishGameWizard(bot TelegramClient, conversation *Conversation) {
+	// The size was checked when it was given, but the configuration may have changed since.
+	maxPlayers, err := getMaxPlayersByTamano(conversation.Size)
+	if err != nil {
+		language := chatLanguage(conversation.ChatID)
+		sendMessage(conversation.ChatID, tr(language, "newgame.error", "error", translateError(language, err)))
+		return
+	}
+	game := newGame(conversation.ChatID, conversation.User.ID, conversation.Size, maxPlayers)
+	game.Date = conversation.Date
+	game.Schedule = conversation.Schedule
+	if len(conversation.Address) > 0 {
+		applyVenue(&game, conversation.Address)
+	}
+	game = createGame(game)
+	language := chatLanguage(game.ChatID)
+
+	response := renderTemplate(game.ChatID, templateCreation, TemplateData{Game: gameTemplateData(game, false), User: HTML(escapeHTML(conversation.User.FirstName))})
+
+	msg := tgbotapi.NewMessage(conversation.ChatID, response)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(emojiThumbsUp+" "+tr(language, "wizard.join"), "yojuego:"+strconv.Itoa(game.Id)),
+	))
+	sendText(msg)
+}
+
+// messageID returns the message with the buttons of the current step, which is set once it is sent.
+func (conversation *Conversation) messageID() int {
+	conversationsMutex.Lock()
+	defer conversationsMutex.Unlock()
+	return conversation.MessageID
+}
+
+func getConversation(chatID int64, userID int) *Conversation {
+	conversationsMutex.Lock()
+	defer conversationsMutex.Unlock()
+	return conversations[conversationKey{chatID, userID}]
+}
+
+// handleConversationMessage feeds a plain text message to the conversation of its author, returning false if there is
+// none. In groups only replies to the prompt count: with privacy mode on they are the only messages the bot gets, and
+// they tell an answer apart from the rest of the chat.
+func handleConversationMessage(bot TelegramClient, message *tgbotapi.Message) bool {
+	if message.From == nil || message.Text == "" {
+		return false
+	}
+	conversation := getConversation(message.Chat.ID, message.From.ID)
+	if conversation == nil {
+		return false
+	}
+	if !message.Chat.IsPrivate() && (message.ReplyToMessage == nil || message.ReplyToMessage.MessageID != conversation.messageID()) {
+		return false
+	}
+	handleWizardAnswer(bot, conversation, message.Text)
+	return true
+}
+
+func handleWizardCallback(bot TelegramClient, query *tgbotapi.CallbackQuery) {
+	if query.Message == nil {
+		return
+	}
+	language := chatLanguage(query.Message.Chat.ID)
+	conversation := getConversation(query.Message.Chat.ID, query.From.ID)
+	if conversation == nil || conversation.messageID() != query.Message.MessageID {
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, tr(language, "wizard.not_yours")))
+		return
+	}
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+
+	action := strings.TrimPrefix(query.Data, "wizard:")
+	switch {
+	case action == "cancel":
+		conversationsMutex.Lock()
+		delete(conversations, conversationKey{conversation.ChatID, conversation.User.ID})
+		conversationsMutex.Unlock()
+		sendMessage(conversation.ChatID, tr(language, "wizard.cancelled", "name", conversation.User.FirstName))
+	case action == "back":
+		conversationsMutex.Lock()
+		if conversation.Step > wizardStepSize {
+			conversation.Step--
+		}
+		conversation.UpdatedAt = clock()
+		conversationsMutex.Unlock()
+		sendWizardStep(bot, conversation)
+	case action == "skip":
+		conversationsMutex.Lock()
+		delete(conversations, conversationKey{conversation.ChatID, conversation.User.ID})
+		conversationsMutex.Unlock()
+		finishGameWizard(bot, conversation)
+	case strings.HasPrefix(action, "o:"):
+		index, err := strconv.Atoi(strings.TrimPrefix(action, "o:"))
+		conversationsMutex.Lock()
+		options := conversation.Options
+		conversationsMutex.Unlock()
+		if err == nil && index >= 0 && index < len(options) {
+			handleWizardAnswer(bot, conversation, options[index])
+		}
+	}
+}
+
+func handleJoinButtonCallback(bot TelegramClient, query *tgbotapi.CallbackQuery) {
+	gameId, _ := strconv.Atoi(strings.TrimPrefix(query.Data, "yojuego:"))
+	game, exists := getGame(gameId)
+	if !exists || !game.Active {
+		language := defaultLanguage
+		if query.Message != nil {
+			language = chatLanguage(query.Message.Chat.ID)
+		}
+		bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, tr(language, "wizard.unavailable")))
+		return
+	}
+	response := joinGame(game, query.From)
+	bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, ""))
+	sendMessage(game.ChatID, response)
+}
+
+// expireConversations drops the conversations nobody answered in a while.
+func expireConversations(now time.Time) {
+	expired := make([]*Conversation, 0)
+	conversationsMutex.Lock()
+	for key, conversation := range conversations {
+		if now.Sub(conversation.UpdatedAt) > wizardTimeout {
+			expired = append(expired, conversation)
+			delete(conversations, key)
+		}
+	}
+	conversationsMutex.Unlock()
+
+	for _, conversation := range expired {
+		sendMessage(conversation.ChatID, tr(chatLanguage(conversation.ChatID), "wizard.expired", "name", conversation.User.FirstName))
+	}
+}
+
+// offeredGameSizes are the configured game sizes the bot allows.
+func offeredGameSizes() []string {
+	config := getConfig()
+	sizes := make([]string, 0, len(config.GameSizes))
+	for _, size := range config.GameSizes {
+		if number, _ := strconv.Atoi(size); number <= config.MaxGameSize {
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes
+}